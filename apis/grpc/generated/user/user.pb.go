@@ -6,6 +6,8 @@ package proto
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import emptypb "google.golang.org/protobuf/types/known/emptypb"
+import fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 
 import (
 	context "golang.org/x/net/context"
@@ -37,7 +39,7 @@ func (m *User) Reset()         { *m = User{} }
 func (m *User) String() string { return proto.CompactTextString(m) }
 func (*User) ProtoMessage()    {}
 func (*User) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_b174730dbc274ebf, []int{0}
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{0}
 }
 func (m *User) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_User.Unmarshal(m, b)
@@ -96,7 +98,7 @@ func (m *Favourite) Reset()         { *m = Favourite{} }
 func (m *Favourite) String() string { return proto.CompactTextString(m) }
 func (*Favourite) ProtoMessage()    {}
 func (*Favourite) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_b174730dbc274ebf, []int{1}
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{1}
 }
 func (m *Favourite) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Favourite.Unmarshal(m, b)
@@ -124,7 +126,11 @@ func (m *Favourite) GetBeers() []string {
 }
 
 type Users struct {
-	Users                []*User  `protobuf:"bytes,1,rep,name=users" json:"users,omitempty"`
+	Users []*User `protobuf:"bytes,1,rep,name=users" json:"users,omitempty"`
+	// next_cursor gets the next page, it is empty on the last page
+	NextCursor string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor" json:"next_cursor,omitempty"`
+	// total is the number of users matching the filters across all the pages
+	Total                int32    `protobuf:"varint,3,opt,name=total" json:"total,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -134,7 +140,7 @@ func (m *Users) Reset()         { *m = Users{} }
 func (m *Users) String() string { return proto.CompactTextString(m) }
 func (*Users) ProtoMessage()    {}
 func (*Users) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_b174730dbc274ebf, []int{2}
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{2}
 }
 func (m *Users) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Users.Unmarshal(m, b)
@@ -161,8 +167,30 @@ func (m *Users) GetUsers() []*User {
 	return nil
 }
 
+func (m *Users) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
+func (m *Users) GetTotal() int32 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+// UserGetRequest identifies a user, or selects a page of the users for GetAll
 type UserGetRequest struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=Id" json:"Id,omitempty"`
+	Id string `protobuf:"bytes,1,opt,name=Id" json:"Id,omitempty"`
+	// The users matching all the filters are listed, in the order of the sorts and then of the IDs
+	Filters []*Filter `protobuf:"bytes,2,rep,name=filters" json:"filters,omitempty"`
+	Sort    []*Sort   `protobuf:"bytes,3,rep,name=sort" json:"sort,omitempty"`
+	// limit is the maximum number of users of the page, 20 by default
+	Limit int32 `protobuf:"varint,4,opt,name=limit" json:"limit,omitempty"`
+	// cursor is the next_cursor of the previous page
+	Cursor               string   `protobuf:"bytes,5,opt,name=cursor" json:"cursor,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -172,7 +200,7 @@ func (m *UserGetRequest) Reset()         { *m = UserGetRequest{} }
 func (m *UserGetRequest) String() string { return proto.CompactTextString(m) }
 func (*UserGetRequest) ProtoMessage()    {}
 func (*UserGetRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_user_b174730dbc274ebf, []int{3}
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{3}
 }
 func (m *UserGetRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UserGetRequest.Unmarshal(m, b)
@@ -199,11 +227,481 @@ func (m *UserGetRequest) GetId() string {
 	return ""
 }
 
+func (m *UserGetRequest) GetFilters() []*Filter {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *UserGetRequest) GetSort() []*Sort {
+	if m != nil {
+		return m.Sort
+	}
+	return nil
+}
+
+func (m *UserGetRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *UserGetRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+// Filter selects the users whose field matches the value with the operator, eq prefix or contains, eq by default
+type Filter struct {
+	Field                string   `protobuf:"bytes,1,opt,name=field" json:"field,omitempty"`
+	Op                   string   `protobuf:"bytes,2,opt,name=op" json:"op,omitempty"`
+	Value                string   `protobuf:"bytes,3,opt,name=value" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Filter) Reset()         { *m = Filter{} }
+func (m *Filter) String() string { return proto.CompactTextString(m) }
+func (*Filter) ProtoMessage()    {}
+func (*Filter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{4}
+}
+func (m *Filter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Filter.Unmarshal(m, b)
+}
+func (m *Filter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Filter.Marshal(b, m, deterministic)
+}
+func (dst *Filter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Filter.Merge(dst, src)
+}
+func (m *Filter) XXX_Size() int {
+	return xxx_messageInfo_Filter.Size(m)
+}
+func (m *Filter) XXX_DiscardUnknown() {
+	xxx_messageInfo_Filter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Filter proto.InternalMessageInfo
+
+func (m *Filter) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *Filter) GetOp() string {
+	if m != nil {
+		return m.Op
+	}
+	return ""
+}
+
+func (m *Filter) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+// Sort orders the users by the field
+type Sort struct {
+	Field                string   `protobuf:"bytes,1,opt,name=field" json:"field,omitempty"`
+	Desc                 bool     `protobuf:"varint,2,opt,name=desc" json:"desc,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Sort) Reset()         { *m = Sort{} }
+func (m *Sort) String() string { return proto.CompactTextString(m) }
+func (*Sort) ProtoMessage()    {}
+func (*Sort) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{5}
+}
+func (m *Sort) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Sort.Unmarshal(m, b)
+}
+func (m *Sort) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Sort.Marshal(b, m, deterministic)
+}
+func (dst *Sort) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Sort.Merge(dst, src)
+}
+func (m *Sort) XXX_Size() int {
+	return xxx_messageInfo_Sort.Size(m)
+}
+func (m *Sort) XXX_DiscardUnknown() {
+	xxx_messageInfo_Sort.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Sort proto.InternalMessageInfo
+
+func (m *Sort) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *Sort) GetDesc() bool {
+	if m != nil {
+		return m.Desc
+	}
+	return false
+}
+
+type UpdateUserRequest struct {
+	// user.id identifies the user to update
+	User *User `protobuf:"bytes,1,opt,name=user" json:"user,omitempty"`
+	// update_mask lists the fields of the user to update, name is the only one that can be updated.
+	// The fields set in the user are updated if there is no mask
+	UpdateMask           *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask" json:"update_mask,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *UpdateUserRequest) Reset()         { *m = UpdateUserRequest{} }
+func (m *UpdateUserRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateUserRequest) ProtoMessage()    {}
+func (*UpdateUserRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{6}
+}
+func (m *UpdateUserRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateUserRequest.Unmarshal(m, b)
+}
+func (m *UpdateUserRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateUserRequest.Marshal(b, m, deterministic)
+}
+func (dst *UpdateUserRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateUserRequest.Merge(dst, src)
+}
+func (m *UpdateUserRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateUserRequest.Size(m)
+}
+func (m *UpdateUserRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateUserRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateUserRequest proto.InternalMessageInfo
+
+func (m *UpdateUserRequest) GetUser() *User {
+	if m != nil {
+		return m.User
+	}
+	return nil
+}
+
+func (m *UpdateUserRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if m != nil {
+		return m.UpdateMask
+	}
+	return nil
+}
+
+type DeleteUserRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteUserRequest) Reset()         { *m = DeleteUserRequest{} }
+func (m *DeleteUserRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteUserRequest) ProtoMessage()    {}
+func (*DeleteUserRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{7}
+}
+func (m *DeleteUserRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteUserRequest.Unmarshal(m, b)
+}
+func (m *DeleteUserRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteUserRequest.Marshal(b, m, deterministic)
+}
+func (dst *DeleteUserRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteUserRequest.Merge(dst, src)
+}
+func (m *DeleteUserRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteUserRequest.Size(m)
+}
+func (m *DeleteUserRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteUserRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteUserRequest proto.InternalMessageInfo
+
+func (m *DeleteUserRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type ListUsersRequest struct {
+	// page_size is the maximum number of users of the page, 20 by default and 100 at most
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+	// The users matching all the filters are listed, in the order of the sorts and then of the IDs
+	Filters              []*Filter `protobuf:"bytes,3,rep,name=filters" json:"filters,omitempty"`
+	Sort                 []*Sort   `protobuf:"bytes,4,rep,name=sort" json:"sort,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ListUsersRequest) Reset()         { *m = ListUsersRequest{} }
+func (m *ListUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*ListUsersRequest) ProtoMessage()    {}
+func (*ListUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{8}
+}
+func (m *ListUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListUsersRequest.Unmarshal(m, b)
+}
+func (m *ListUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListUsersRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListUsersRequest.Merge(dst, src)
+}
+func (m *ListUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_ListUsersRequest.Size(m)
+}
+func (m *ListUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListUsersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListUsersRequest proto.InternalMessageInfo
+
+func (m *ListUsersRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListUsersRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *ListUsersRequest) GetFilters() []*Filter {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *ListUsersRequest) GetSort() []*Sort {
+	if m != nil {
+		return m.Sort
+	}
+	return nil
+}
+
+type ListUsersResponse struct {
+	Users []*User `protobuf:"bytes,1,rep,name=users" json:"users,omitempty"`
+	// next_page_token gets the next page, it is empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
+	// total_size is the number of users matching the filters across all the pages
+	TotalSize            int32    `protobuf:"varint,3,opt,name=total_size,json=totalSize" json:"total_size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListUsersResponse) Reset()         { *m = ListUsersResponse{} }
+func (m *ListUsersResponse) String() string { return proto.CompactTextString(m) }
+func (*ListUsersResponse) ProtoMessage()    {}
+func (*ListUsersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{9}
+}
+func (m *ListUsersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListUsersResponse.Unmarshal(m, b)
+}
+func (m *ListUsersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListUsersResponse.Marshal(b, m, deterministic)
+}
+func (dst *ListUsersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListUsersResponse.Merge(dst, src)
+}
+func (m *ListUsersResponse) XXX_Size() int {
+	return xxx_messageInfo_ListUsersResponse.Size(m)
+}
+func (m *ListUsersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListUsersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListUsersResponse proto.InternalMessageInfo
+
+func (m *ListUsersResponse) GetUsers() []*User {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+func (m *ListUsersResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func (m *ListUsersResponse) GetTotalSize() int32 {
+	if m != nil {
+		return m.TotalSize
+	}
+	return 0
+}
+
+type BatchGetUsersRequest struct {
+	// ids are the ids of the users, 100 at most
+	Ids                  []string `protobuf:"bytes,1,rep,name=ids" json:"ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BatchGetUsersRequest) Reset()         { *m = BatchGetUsersRequest{} }
+func (m *BatchGetUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchGetUsersRequest) ProtoMessage()    {}
+func (*BatchGetUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{10}
+}
+func (m *BatchGetUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchGetUsersRequest.Unmarshal(m, b)
+}
+func (m *BatchGetUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchGetUsersRequest.Marshal(b, m, deterministic)
+}
+func (dst *BatchGetUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchGetUsersRequest.Merge(dst, src)
+}
+func (m *BatchGetUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_BatchGetUsersRequest.Size(m)
+}
+func (m *BatchGetUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchGetUsersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchGetUsersRequest proto.InternalMessageInfo
+
+func (m *BatchGetUsersRequest) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+type BatchGetUsersResponse struct {
+	Users                []*User  `protobuf:"bytes,1,rep,name=users" json:"users,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BatchGetUsersResponse) Reset()         { *m = BatchGetUsersResponse{} }
+func (m *BatchGetUsersResponse) String() string { return proto.CompactTextString(m) }
+func (*BatchGetUsersResponse) ProtoMessage()    {}
+func (*BatchGetUsersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{11}
+}
+func (m *BatchGetUsersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchGetUsersResponse.Unmarshal(m, b)
+}
+func (m *BatchGetUsersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchGetUsersResponse.Marshal(b, m, deterministic)
+}
+func (dst *BatchGetUsersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchGetUsersResponse.Merge(dst, src)
+}
+func (m *BatchGetUsersResponse) XXX_Size() int {
+	return xxx_messageInfo_BatchGetUsersResponse.Size(m)
+}
+func (m *BatchGetUsersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchGetUsersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchGetUsersResponse proto.InternalMessageInfo
+
+func (m *BatchGetUsersResponse) GetUsers() []*User {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+type ExportUsersRequest struct {
+	Filters              []*Filter `protobuf:"bytes,1,rep,name=filters" json:"filters,omitempty"`
+	Sort                 []*Sort   `protobuf:"bytes,2,rep,name=sort" json:"sort,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ExportUsersRequest) Reset()         { *m = ExportUsersRequest{} }
+func (m *ExportUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportUsersRequest) ProtoMessage()    {}
+func (*ExportUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_user_e3b0bab56e3b19b6, []int{12}
+}
+func (m *ExportUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportUsersRequest.Unmarshal(m, b)
+}
+func (m *ExportUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportUsersRequest.Marshal(b, m, deterministic)
+}
+func (dst *ExportUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportUsersRequest.Merge(dst, src)
+}
+func (m *ExportUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_ExportUsersRequest.Size(m)
+}
+func (m *ExportUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportUsersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportUsersRequest proto.InternalMessageInfo
+
+func (m *ExportUsersRequest) GetFilters() []*Filter {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *ExportUsersRequest) GetSort() []*Sort {
+	if m != nil {
+		return m.Sort
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*User)(nil), "proto.User")
 	proto.RegisterType((*Favourite)(nil), "proto.Favourite")
 	proto.RegisterType((*Users)(nil), "proto.Users")
 	proto.RegisterType((*UserGetRequest)(nil), "proto.UserGetRequest")
+	proto.RegisterType((*Filter)(nil), "proto.Filter")
+	proto.RegisterType((*Sort)(nil), "proto.Sort")
+	proto.RegisterType((*UpdateUserRequest)(nil), "proto.UpdateUserRequest")
+	proto.RegisterType((*DeleteUserRequest)(nil), "proto.DeleteUserRequest")
+	proto.RegisterType((*ListUsersRequest)(nil), "proto.ListUsersRequest")
+	proto.RegisterType((*ListUsersResponse)(nil), "proto.ListUsersResponse")
+	proto.RegisterType((*BatchGetUsersRequest)(nil), "proto.BatchGetUsersRequest")
+	proto.RegisterType((*BatchGetUsersResponse)(nil), "proto.BatchGetUsersResponse")
+	proto.RegisterType((*ExportUsersRequest)(nil), "proto.ExportUsersRequest")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -220,7 +718,16 @@ type UserServiceClient interface {
 	GetAll(ctx context.Context, in *UserGetRequest, opts ...grpc.CallOption) (*Users, error)
 	GetOne(ctx context.Context, in *UserGetRequest, opts ...grpc.CallOption) (*User, error)
 	GetWithInfo(ctx context.Context, in *UserGetRequest, opts ...grpc.CallOption) (*User, error)
+	// Insert stores the user and returns it with the id assigned by the server
 	Insert(ctx context.Context, in *User, opts ...grpc.CallOption) (*User, error)
+	// UpdateUser updates the fields of the update_mask and returns the updated user
+	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*User, error)
+	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	// BatchGetUsers gets the users in the order of the ids, it fails if any of them does not exist
+	BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersResponse, error)
+	// ExportUsers streams all the users matching the filters, in the order of the sorts and then of the IDs
+	ExportUsers(ctx context.Context, in *ExportUsersRequest, opts ...grpc.CallOption) (UserService_ExportUsersClient, error)
 }
 
 type userServiceClient struct {
@@ -267,13 +774,90 @@ func (c *userServiceClient) Insert(ctx context.Context, in *User, opts ...grpc.C
 	return out, nil
 }
 
+func (c *userServiceClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := grpc.Invoke(ctx, "/proto.UserService/UpdateUser", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := grpc.Invoke(ctx, "/proto.UserService/DeleteUser", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
+	out := new(ListUsersResponse)
+	err := grpc.Invoke(ctx, "/proto.UserService/ListUsers", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersResponse, error) {
+	out := new(BatchGetUsersResponse)
+	err := grpc.Invoke(ctx, "/proto.UserService/BatchGetUsers", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ExportUsers(ctx context.Context, in *ExportUsersRequest, opts ...grpc.CallOption) (UserService_ExportUsersClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_UserService_serviceDesc.Streams[0], c.cc, "/proto.UserService/ExportUsers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceExportUsersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UserService_ExportUsersClient interface {
+	Recv() (*User, error)
+	grpc.ClientStream
+}
+
+type userServiceExportUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceExportUsersClient) Recv() (*User, error) {
+	m := new(User)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for UserService service
 
 type UserServiceServer interface {
 	GetAll(context.Context, *UserGetRequest) (*Users, error)
 	GetOne(context.Context, *UserGetRequest) (*User, error)
 	GetWithInfo(context.Context, *UserGetRequest) (*User, error)
+	// Insert stores the user and returns it with the id assigned by the server
 	Insert(context.Context, *User) (*User, error)
+	// UpdateUser updates the fields of the update_mask and returns the updated user
+	UpdateUser(context.Context, *UpdateUserRequest) (*User, error)
+	DeleteUser(context.Context, *DeleteUserRequest) (*emptypb.Empty, error)
+	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
+	// BatchGetUsers gets the users in the order of the ids, it fails if any of them does not exist
+	BatchGetUsers(context.Context, *BatchGetUsersRequest) (*BatchGetUsersResponse, error)
+	// ExportUsers streams all the users matching the filters, in the order of the sorts and then of the IDs
+	ExportUsers(*ExportUsersRequest, UserService_ExportUsersServer) error
 }
 
 func RegisterUserServiceServer(s *grpc.Server, srv UserServiceServer) {
@@ -352,6 +936,99 @@ func _UserService_Insert_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).UpdateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.UserService/UpdateUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_DeleteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).DeleteUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.UserService/DeleteUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).DeleteUser(ctx, req.(*DeleteUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.UserService/ListUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ListUsers(ctx, req.(*ListUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_BatchGetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).BatchGetUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.UserService/BatchGetUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).BatchGetUsers(ctx, req.(*BatchGetUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ExportUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportUsersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserServiceServer).ExportUsers(m, &userServiceExportUsersServer{stream})
+}
+
+type UserService_ExportUsersServer interface {
+	Send(*User) error
+	grpc.ServerStream
+}
+
+type userServiceExportUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceExportUsersServer) Send(m *User) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _UserService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.UserService",
 	HandlerType: (*UserServiceServer)(nil),
@@ -372,30 +1049,80 @@ var _UserService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Insert",
 			Handler:    _UserService_Insert_Handler,
 		},
+		{
+			MethodName: "UpdateUser",
+			Handler:    _UserService_UpdateUser_Handler,
+		},
+		{
+			MethodName: "DeleteUser",
+			Handler:    _UserService_DeleteUser_Handler,
+		},
+		{
+			MethodName: "ListUsers",
+			Handler:    _UserService_ListUsers_Handler,
+		},
+		{
+			MethodName: "BatchGetUsers",
+			Handler:    _UserService_BatchGetUsers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportUsers",
+			Handler:       _UserService_ExportUsers_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "user.proto",
 }
 
-func init() { proto.RegisterFile("user.proto", fileDescriptor_user_b174730dbc274ebf) }
-
-var fileDescriptor_user_b174730dbc274ebf = []byte{
-	// 261 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x8f, 0x41, 0x4b, 0xfb, 0x40,
-	0x10, 0xc5, 0xd9, 0x34, 0x09, 0x74, 0xf6, 0x4f, 0xf9, 0x33, 0x28, 0x2c, 0x9e, 0xd2, 0x3d, 0x15,
-	0xd1, 0x80, 0xf1, 0x13, 0x78, 0x31, 0xe4, 0x24, 0x44, 0xc4, 0x73, 0x6a, 0x46, 0x5c, 0xa8, 0x49,
-	0xdd, 0x9d, 0xf4, 0xfb, 0xf9, 0xcd, 0x64, 0x37, 0xd2, 0xae, 0x07, 0xc1, 0x53, 0x66, 0xde, 0xfb,
-	0xe5, 0xed, 0x1b, 0x80, 0xc9, 0x91, 0x2d, 0xf7, 0x76, 0xe4, 0x11, 0xb3, 0xf0, 0xd1, 0x7b, 0x48,
-	0x9f, 0x1c, 0x59, 0x5c, 0x41, 0x62, 0x7a, 0x25, 0x0a, 0xb1, 0x59, 0xb6, 0x89, 0xe9, 0x11, 0x21,
-	0x1d, 0xba, 0x77, 0x52, 0x49, 0x50, 0xc2, 0x8c, 0x67, 0x90, 0x39, 0xee, 0xac, 0x53, 0x8b, 0x20,
-	0xce, 0x0b, 0x96, 0xb0, 0xbc, 0xef, 0x0e, 0xe3, 0x64, 0x0d, 0x93, 0x4a, 0x0b, 0xb1, 0x91, 0xd5,
-	0xff, 0xf9, 0x8d, 0xf2, 0xa8, 0xb7, 0x27, 0x44, 0xaf, 0x23, 0xde, 0x47, 0x6e, 0x89, 0xac, 0x53,
-	0xa2, 0x58, 0xf8, 0xc8, 0xb0, 0xe8, 0x4b, 0xc8, 0x7c, 0x29, 0x87, 0x6b, 0xc8, 0x7c, 0xe5, 0xd9,
-	0x96, 0x95, 0xfc, 0xce, 0xf5, 0x66, 0x3b, 0x3b, 0xba, 0x80, 0x95, 0x5f, 0x6b, 0xe2, 0x96, 0x3e,
-	0x26, 0x72, 0xec, 0x4f, 0x69, 0x8e, 0xa7, 0x34, 0x7d, 0xf5, 0x29, 0x40, 0x7a, 0xe4, 0x91, 0xec,
-	0xc1, 0xbc, 0x10, 0x5e, 0x43, 0x5e, 0x13, 0xdf, 0xed, 0x76, 0x78, 0x1e, 0xe5, 0x9d, 0x02, 0x2e,
-	0xfe, 0x45, 0xb2, 0xc3, 0xab, 0x80, 0x3f, 0x0c, 0xf4, 0x1b, 0x1e, 0xb7, 0xc2, 0x1b, 0x90, 0x35,
-	0xf1, 0xb3, 0xe1, 0xb7, 0x66, 0x78, 0x1d, 0xff, 0xf4, 0x8b, 0x86, 0xbc, 0x19, 0x1c, 0x59, 0xc6,
-	0x58, 0xfe, 0xc1, 0x6c, 0xf3, 0x30, 0xdf, 0x7e, 0x05, 0x00, 0x00, 0xff, 0xff, 0x73, 0xba, 0x26,
-	0xe8, 0xc2, 0x01, 0x00, 0x00,
+func init() { proto.RegisterFile("user.proto", fileDescriptor_user_e3b0bab56e3b19b6) }
+
+var fileDescriptor_user_e3b0bab56e3b19b6 = []byte{
+	// 709 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0x51, 0x4f, 0xdb, 0x48,
+	0x10, 0x56, 0x12, 0x3b, 0x47, 0xc6, 0x07, 0x07, 0x2b, 0xe0, 0x7c, 0xe6, 0x10, 0xc1, 0x95, 0x5a,
+	0x1e, 0xda, 0x40, 0xc3, 0x43, 0xa5, 0x56, 0x42, 0x6a, 0x0b, 0x44, 0xa9, 0x5a, 0xb5, 0x72, 0x8a,
+	0xfa, 0x56, 0x64, 0xe2, 0x09, 0x58, 0x38, 0x5e, 0xe3, 0x5d, 0x23, 0x8a, 0xd4, 0xff, 0xd1, 0xff,
+	0xda, 0x97, 0x6a, 0xc7, 0xeb, 0xc4, 0x4e, 0x40, 0xca, 0x53, 0x3c, 0x33, 0xdf, 0xcc, 0x7c, 0x3b,
+	0xf3, 0x4d, 0x00, 0x32, 0x81, 0x69, 0x27, 0x49, 0xb9, 0xe4, 0xcc, 0xa4, 0x1f, 0x67, 0xeb, 0x92,
+	0xf3, 0xcb, 0x08, 0xf7, 0xc9, 0xba, 0xc8, 0x46, 0xfb, 0x38, 0x4e, 0xe4, 0x8f, 0x1c, 0xe3, 0xb4,
+	0x67, 0x83, 0xa3, 0x10, 0xa3, 0xe0, 0x7c, 0xec, 0x8b, 0xeb, 0x1c, 0xe1, 0x26, 0x60, 0x9c, 0x09,
+	0x4c, 0xd9, 0x0a, 0xd4, 0xc3, 0xc0, 0xae, 0xb5, 0x6b, 0x7b, 0x2d, 0xaf, 0x1e, 0x06, 0x8c, 0x81,
+	0x11, 0xfb, 0x63, 0xb4, 0xeb, 0xe4, 0xa1, 0x6f, 0xb6, 0x0e, 0xa6, 0x90, 0x7e, 0x2a, 0xec, 0x06,
+	0x39, 0x73, 0x83, 0x75, 0xa0, 0x75, 0xea, 0xdf, 0xf2, 0x2c, 0x0d, 0x25, 0xda, 0x46, 0xbb, 0xb6,
+	0x67, 0x75, 0x57, 0xf3, 0xe2, 0x9d, 0x89, 0xdf, 0x9b, 0x42, 0xdc, 0xdd, 0x12, 0x5e, 0x95, 0xbc,
+	0x40, 0x4c, 0x85, 0x5d, 0x6b, 0x37, 0x54, 0x49, 0x32, 0x5c, 0x1f, 0x4c, 0x45, 0x4a, 0xb0, 0x5d,
+	0x30, 0x33, 0x51, 0x84, 0xad, 0xae, 0xa5, 0xeb, 0xaa, 0xa0, 0x97, 0x47, 0xd8, 0x0e, 0x58, 0x31,
+	0xde, 0xc9, 0xf3, 0x61, 0x96, 0x0a, 0x9e, 0x6a, 0xbe, 0xa0, 0x5c, 0xef, 0xc9, 0xa3, 0x5a, 0x48,
+	0x2e, 0xfd, 0x88, 0x58, 0x9b, 0x5e, 0x6e, 0xb8, 0xbf, 0x6a, 0xb0, 0xa2, 0xca, 0xf4, 0x50, 0x7a,
+	0x78, 0x93, 0xa1, 0x90, 0x6a, 0x04, 0xfd, 0xc9, 0x08, 0xfa, 0x01, 0x7b, 0x06, 0x7f, 0x8d, 0xc2,
+	0x48, 0xaa, 0xf6, 0x75, 0x6a, 0xbf, 0x5c, 0x3c, 0x8b, 0xbc, 0x5e, 0x11, 0x65, 0x3b, 0x60, 0x08,
+	0x9e, 0x4a, 0xbb, 0x51, 0x21, 0x39, 0xe0, 0xa9, 0xf4, 0x28, 0xa0, 0x28, 0x44, 0xe1, 0x38, 0x94,
+	0x34, 0x1e, 0xd3, 0xcb, 0x0d, 0xb6, 0x09, 0x4d, 0x4d, 0xda, 0xa4, 0x9e, 0xda, 0x72, 0x8f, 0xa1,
+	0x99, 0x77, 0x50, 0x79, 0xb4, 0x30, 0x4d, 0x2a, 0x37, 0x14, 0x4f, 0x9e, 0xe8, 0x87, 0xd6, 0x79,
+	0xa2, 0x50, 0xb7, 0x7e, 0x94, 0x61, 0xb1, 0x16, 0x32, 0xdc, 0x03, 0x30, 0x06, 0xba, 0xf7, 0x03,
+	0x35, 0x18, 0x18, 0x01, 0x8a, 0x21, 0x55, 0x59, 0xf2, 0xe8, 0xdb, 0xbd, 0x81, 0xb5, 0xb3, 0x24,
+	0xf0, 0x25, 0xd2, 0x78, 0xf5, 0x50, 0x76, 0xc0, 0x50, 0x73, 0xa6, 0xec, 0x99, 0x05, 0x50, 0x80,
+	0xbd, 0x01, 0x2b, 0xa3, 0x2c, 0x52, 0x15, 0x15, 0xb4, 0xba, 0x4e, 0x27, 0x17, 0x5e, 0xa7, 0x10,
+	0x5e, 0xe7, 0x54, 0xb5, 0xfd, 0xe4, 0x8b, 0x6b, 0x0f, 0x72, 0xb8, 0xfa, 0x76, 0x9f, 0xc0, 0xda,
+	0x31, 0x46, 0x58, 0x6d, 0x39, 0x23, 0x45, 0xb5, 0xaa, 0xd5, 0x8f, 0xa1, 0x90, 0x0a, 0x23, 0x0a,
+	0xd0, 0x16, 0xb4, 0x12, 0xff, 0x12, 0xcf, 0x45, 0x78, 0x8f, 0x84, 0x35, 0xbd, 0x25, 0xe5, 0x18,
+	0x84, 0xf7, 0xc8, 0xb6, 0x01, 0x28, 0x28, 0xf9, 0x35, 0xc6, 0x7a, 0x52, 0x04, 0xff, 0xaa, 0x1c,
+	0xe5, 0xc5, 0x36, 0x16, 0x5a, 0xac, 0xf1, 0xc8, 0x62, 0xdd, 0x9f, 0xb0, 0x56, 0x62, 0x26, 0x12,
+	0x1e, 0x0b, 0x5c, 0x44, 0xb4, 0x4f, 0xe1, 0x1f, 0x12, 0xed, 0x1c, 0xcb, 0x65, 0xe5, 0xfe, 0x32,
+	0x61, 0xba, 0x0d, 0x40, 0x72, 0xcd, 0x9f, 0x99, 0x0b, 0xb8, 0x45, 0x1e, 0xf5, 0x4e, 0x77, 0x0f,
+	0xd6, 0xdf, 0xf9, 0x72, 0x78, 0xd5, 0xc3, 0xea, 0x70, 0x56, 0xa1, 0x11, 0x06, 0xc5, 0x4d, 0xa9,
+	0x4f, 0xf7, 0x35, 0x6c, 0xcc, 0x20, 0x17, 0x26, 0xeb, 0x7e, 0x07, 0x76, 0x72, 0x97, 0xf0, 0xb4,
+	0xda, 0xa3, 0x34, 0xc4, 0xda, 0x42, 0x43, 0xac, 0x3f, 0x32, 0xc4, 0xee, 0xef, 0x06, 0x58, 0xaa,
+	0xf4, 0x00, 0xd3, 0xdb, 0x70, 0x88, 0xec, 0x05, 0x34, 0x7b, 0x28, 0xdf, 0x46, 0x11, 0xdb, 0x28,
+	0xb1, 0x99, 0x1e, 0xaa, 0xf3, 0x77, 0xc9, 0x2d, 0xd8, 0x73, 0x82, 0x7f, 0x8e, 0xf1, 0x31, 0x78,
+	0xf9, 0x4d, 0xec, 0x25, 0x58, 0x3d, 0x94, 0xdf, 0x42, 0x79, 0xd5, 0x8f, 0x47, 0x7c, 0xa1, 0x14,
+	0x17, 0x9a, 0xfd, 0x58, 0x60, 0x2a, 0x59, 0xd9, 0x5d, 0xc5, 0x1c, 0x02, 0x4c, 0x6f, 0x87, 0xd9,
+	0x45, 0x68, 0xf6, 0x9c, 0xaa, 0x49, 0x47, 0x00, 0x53, 0xf5, 0x4f, 0x92, 0xe6, 0x0e, 0xc2, 0xd9,
+	0x9c, 0xbb, 0xa6, 0x13, 0xf5, 0x1f, 0xcf, 0x8e, 0xa0, 0x35, 0x51, 0x1f, 0xfb, 0x57, 0xa7, 0xcf,
+	0x5e, 0x8a, 0x63, 0xcf, 0x07, 0xf4, 0xee, 0x3f, 0xc0, 0x72, 0x45, 0x14, 0x6c, 0x4b, 0x43, 0x1f,
+	0x12, 0x95, 0xf3, 0xff, 0xc3, 0x41, 0x5d, 0xeb, 0x15, 0x58, 0x25, 0x91, 0xb0, 0xff, 0x34, 0x78,
+	0x5e, 0x38, 0x95, 0x11, 0x1c, 0xd4, 0x2e, 0x9a, 0x64, 0x1d, 0xfe, 0x19, 0x00, 0x37, 0x02, 0x72,
+	0x10, 0xdb, 0x06, 0x00, 0x00,
 }
@@ -1,19 +1,32 @@
 package grpc
 
 import (
+	"context"
+	"crypto/tls"
 	ierror "errors"
+	"fmt"
 	"go-boilerplate-api/apis/grpc/utils"
+	"go-boilerplate-api/apis/middleware/accessloggrpc"
 	"go-boilerplate-api/apis/middleware/apmgrpc"
-	"go-boilerplate-api/apm"
+	"go-boilerplate-api/apis/middleware/identitygrpc"
+	"go-boilerplate-api/apis/middleware/metricsgrpc"
+	"go-boilerplate-api/apis/middleware/requestidgrpc"
+	"go-boilerplate-api/config"
 	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/tlsconfig"
 	"go-boilerplate-api/shared"
 	"net"
+	"strconv"
 	"sync"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	"github.com/ralstan-vaz/go-errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpcHealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -21,62 +34,159 @@ const (
 	grpcNetwork string = "tcp"
 )
 
-// StartServer starts the grpc server using the dependencies passed to it
-func StartServer(deps *shared.Deps, wg *sync.WaitGroup, fatalError chan error) error {
-	address := deps.Config.Get().Server.GRPC.Address
+// Server wraps the grpc server so that it can be drained on shutdown
+type Server struct {
+	server       *grpc.Server
+	healthServer *grpcHealth.Server
+	// stopHealth stops the periodic health checks
+	stopHealth context.CancelFunc
+	// reloader reloads the certificates of the server, nil when TLS is disabled
+	reloader *tlsconfig.Reloader
+}
+
+// StartServer starts the grpc server using the dependencies passed to it.
+// The server runs in its own goroutine, the returned Server is used to shut it down
+func StartServer(deps *shared.Deps, wg *sync.WaitGroup, fatalError chan error) *Server {
+	grpcConf := deps.Config.Get().Server.GRPC
+	address := grpcConf.Address
+
+	// The certificates are loaded before the server starts, a failure is reported like a listener failure
+	var opts []grpc.ServerOption
+	var reloader *tlsconfig.Reloader
+	var tlsErr error
+	if grpcConf.TLS.Enabled {
+		var tlsConfig *tls.Config
+		tlsConfig, reloader, tlsErr = tlsconfig.NewServerConfig(grpcConf.TLS.Options(), deps.Config.Get().Reload.Interval)
+		if tlsErr == nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+	}
 
-	var server *grpc.Server
+	// Health is checked until the server is drained
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	server, healthServer := NewServer(healthCtx, deps, opts...)
+
+	go func() {
+		// Go routine finished
+		defer wg.Done()
+
+		if tlsErr != nil {
+			fatalError <- tlsErr
+			return
+		}
+
+		// Creates TCP listener at a particular port
+		lis, err := net.Listen(grpcNetwork, address)
+		if err != nil {
+			fatalError <- errors.NewInternalError(err).SetCode("APIS.GRPC.LISTENER_FAILED")
+			return
+		}
+
+		// Logs server address
+		log.Debug("GRPC Server listening on : " + address + ", TLS: " + strconv.FormatBool(grpcConf.TLS.Enabled) + ", Version: " + shared.VERSION)
+
+		// Links server to the listener, returns nil once the server is stopped
+		err = server.Serve(lis)
+		if err != nil {
+			fatalError <- errors.NewInternalError(err).SetCode("APIS.GRPC.LISTENER_LINK_FAILED")
+		}
+	}()
+
+	return &Server{server: server, healthServer: healthServer, stopHealth: stopHealth, reloader: reloader}
+}
+
+// NewServer creates the grpc server with the interceptors and all the services registered, the options eg. the credentials are added to the server.
+// The serving status reported by the health service is refreshed until the context is cancelled
+func NewServer(ctx context.Context, deps *shared.Deps, serverOpts ...grpc.ServerOption) (*grpc.Server, *grpcHealth.Server) {
 	// Add required opts
 	recoveryOpts := []grpc_recovery.Option{
-		grpc_recovery.WithRecoveryHandler(handlePanic),
+		grpc_recovery.WithRecoveryHandlerContext(panicHandler(deps.Config)),
 	}
 
 	apmOpts := []apmgrpc.Option{
-		apmgrpc.WithAPM(apm.APM),
+		apmgrpc.WithAPM(deps.Apm),
 	}
 
 	opts := []grpc.ServerOption{
 		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
+			metricsgrpc.UnaryServerInterceptor(deps.Metrics),
+			// Identifies the request, before the other interceptors so that their logs carry the request ID
+			requestidgrpc.UnaryServerInterceptor(),
+			// Stores the identity of the client authenticated with its certificate, if any
+			identitygrpc.UnaryServerInterceptor(),
 			apmgrpc.UnaryServerInterceptor(apmOpts...),
+			// Writes the access logs, after the apm so that they carry the trace ID
+			accessloggrpc.UnaryServerInterceptor(deps.Config),
 			grpc_recovery.UnaryServerInterceptor(recoveryOpts...),
 		)),
+		// The streams go through the same interceptors, in the same order
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(
+			metricsgrpc.StreamServerInterceptor(deps.Metrics),
+			requestidgrpc.StreamServerInterceptor(),
+			identitygrpc.StreamServerInterceptor(),
+			apmgrpc.StreamServerInterceptor(apmOpts...),
+			accessloggrpc.StreamServerInterceptor(deps.Config),
+			grpc_recovery.StreamServerInterceptor(recoveryOpts...),
+		)),
 	}
 
 	// Creates new GRPC server
-	server = grpc.NewServer(opts...)
+	server := grpc.NewServer(append(opts, serverOpts...)...)
 
 	registerService(server, deps)
 
-	// Creates TCP listener at a particular port
-	lis, err := net.Listen(grpcNetwork, address)
-	if err != nil {
-		newErr := errors.NewInternalError(err).SetCode("APIS.GRPC.LISTENER_FAILED")
-		fatalError <- newErr
-	}
+	// Health is registered after the services so that it reports their serving status
+	healthServer := registerHealth(ctx, server, deps.Health, deps.Config.Get().Server.GRPC.HealthCheckInterval)
 
-	// Logs server address
-	log.Debug("GRPC Server listening on : " + address + ", Version: " + shared.VERSION)
+	registerReflection(server, deps)
 
-	// Links server to the listener
-	err = server.Serve(lis)
-	if err != nil {
-		newErr := errors.NewInternalError(err).SetCode("APIS.GRPC.LISTENER_LINK_FAILED")
-		fatalError <- newErr
+	return server, healthServer
+}
+
+// Drain marks all the services as not serving so that the clients stop sending new RPCs
+func (s *Server) Drain() {
+	s.stopHealth()
+	s.healthServer.Shutdown()
+}
+
+// Shutdown stops the server from accepting new connections and RPCs and waits for the pending RPCs to finish.
+// If the context expires before the RPCs finish the server is stopped forcefully
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.reloader != nil {
+		defer s.reloader.Close()
 	}
 
-	// Go routine finished (can also be deferred)
-	wg.Done()
+	s.Drain()
 
-	return nil
-}
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
 
-// handlePanic handles unhandled panics by sending an error response for GRPC handlers
-func handlePanic(p interface{}) error {
-	err, ok := p.(error)
-	if !ok {
-		newErr := ierror.New("Panic recovery failed to parse error : " + err.Error())
-		return utils.HandleError(&newErr)
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return errors.NewInternalError(ctx.Err()).SetCode("APIS.GRPC.SHUTDOWN_FAILED")
 	}
+}
 
-	return utils.HandleError(&err)
+// panicHandler handles unhandled panics by sending an error response for GRPC handlers.
+// The error of the panic is sent to the client only if redaction.exposeStackTrace is set
+func panicHandler(conf config.IConfig) grpc_recovery.RecoveryHandlerFuncContext {
+	return func(ctx context.Context, p interface{}) error {
+		err, ok := p.(error)
+		if !ok {
+			err = ierror.New("Panic recovery failed to parse error : " + fmt.Sprint(p))
+		}
+
+		if conf.Get().Redaction.ExposeStackTrace {
+			return utils.HandleError(ctx, &err)
+		}
+
+		log.FromContext(ctx).Error("GO-BOILERPLATE.PANIC", "Unexpected panic occured", log.Priority1, nil, map[string]interface{}{"error": err.Error()})
+		return status.Error(codes.Internal, "Panic: Unexpected error occured.")
+	}
 }
@@ -0,0 +1,261 @@
+package grpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "go-boilerplate-api/apis/grpc/generated/user"
+	"go-boilerplate-api/apm"
+	"go-boilerplate-api/config"
+	"go-boilerplate-api/pkg/clients/db"
+	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/requestid"
+	"go-boilerplate-api/shared"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	testRequestID = "export-request-1"
+	testTraceID   = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+// staticConfig is an IConfig that always returns the same config
+type staticConfig struct {
+	conf *config.Config
+}
+
+func (s *staticConfig) Get() *config.Config                        { return s.conf }
+func (s *staticConfig) Subscribe(fn func(old, new *config.Config)) {}
+func (s *staticConfig) Close() error                               { return nil }
+
+// testCert is a generated certificate along with its key
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert generates a certificate with the common name signed by the parent, a self-signed CA if there is none.
+// The leaf certificates are valid for localhost and usable by both the servers and the clients
+func newTestCert(t *testing.T, commonName string, parent *testCert) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		template.DNSNames = []string{"localhost"}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCert{cert: cert, key: key}
+}
+
+// certificate returns the certificate in the form of the tls configs
+func (c testCert) certificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+// captureLogs returns what the logger writes while fn runs
+func captureLogs(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, logger := os.Stdout, os.Stderr, log.Logger
+	os.Stdout, os.Stderr = w, w
+	defer func() {
+		os.Stdout, os.Stderr, log.Logger = stdout, stderr, logger
+	}()
+
+	log.InitLogger()
+	fn()
+	w.Close()
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// accessLog returns the fields of the first access log of the method in the logs
+func accessLog(t *testing.T, logs string, method string) map[string]interface{} {
+	scanner := bufio.NewScanner(bytes.NewBufferString(logs))
+	for scanner.Scan() {
+		var line struct {
+			Description string `json:"description"`
+			Reference   struct {
+				Payload string `json:"payload"`
+			} `json:"reference"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &line) != nil || line.Description != "GRPC request" {
+			continue
+		}
+
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line.Reference.Payload), &fields); err != nil {
+			t.Fatal(err)
+		}
+		if fields["method"] == method {
+			return fields
+		}
+	}
+	t.Fatalf("no access log of %s in %s", method, logs)
+	return nil
+}
+
+// newTestOtelHandler creates an OtelHandler sampling every trace and appending the spans to a file of the directory
+func newTestOtelHandler(t *testing.T, dir string) *apm.OtelHandler {
+	handler, err := apm.NewOtelHandler(config.Otel{Exporter: apm.ExporterFile, File: filepath.Join(dir, "spans.json"), SampleRatio: 1}, shared.VERSION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return handler
+}
+
+func TestExportUsersThroughInterceptors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", nil)
+	server := newTestCert(t, "go-boilerplate", &ca)
+	client := newTestCert(t, "export-client", &ca)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	conf := &config.Config{}
+	conf.Log.Access.Enabled = true
+	conf.Log.Access.SuccessSampleRate = 1
+	otelHandler := newTestOtelHandler(t, dir)
+	defer otelHandler.Close()
+	deps := &shared.Deps{Config: &staticConfig{conf: conf}, Database: &db.Instances{MyDB: db.NewMyDB()}, Apm: otelHandler}
+
+	// The server requires the client certificates so that the identity of the client reaches the context
+	serverCreds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{server.certificate()},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	grpcServer, _ := NewServer(ctx, deps, grpc.Creds(serverCreds))
+
+	lis := bufconn.Listen(1024 * 1024)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	clientCreds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{client.certificate()},
+		RootCAs:      pool,
+		ServerName:   "localhost",
+	})
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(clientCreds),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var received []*pb.User
+	var trailer metadata.MD
+	req := &pb.ExportUsersRequest{Sort: []*pb.Sort{{Field: "name", Desc: true}}}
+	logs := captureLogs(t, func() {
+		outgoing := metadata.NewOutgoingContext(ctx, metadata.Pairs(
+			requestid.MetadataKey, testRequestID,
+			"traceparent", "00-"+testTraceID+"-00f067aa0ba902b7-01",
+		))
+		stream, err := pb.NewUserServiceClient(conn).ExportUsers(outgoing, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for {
+			u, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			received = append(received, u)
+		}
+		trailer = stream.Trailer()
+	})
+
+	// The users of the in-memory database are streamed in the order of the request
+	assert.Len(t, received, 3)
+	assert.Equal(t, "Tali", received[0].Name)
+	// The request ID is sent back in the trailer of the stream
+	assert.Equal(t, []string{testRequestID}, trailer.Get(requestid.MetadataKey))
+
+	bytesOut := 0
+	for _, u := range received {
+		bytesOut += proto.Size(u)
+	}
+
+	fields := accessLog(t, logs, "/proto.UserService/ExportUsers")
+	assert.Equal(t, "OK", fields["code"])
+	// The counting stream counts the messages and their size on both sides
+	assert.Equal(t, float64(1), fields["messagesIn"])
+	assert.Equal(t, float64(proto.Size(req)), fields["bytesIn"])
+	assert.Equal(t, float64(3), fields["messagesOut"])
+	assert.Equal(t, float64(bytesOut), fields["bytesOut"])
+	// The context wrapped by the interceptors scopes the logs of the stream
+	assert.Equal(t, testRequestID, fields["requestId"])
+	assert.Equal(t, "export-client", fields["clientCn"])
+	assert.Equal(t, testTraceID, fields["traceId"])
+}
@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"context"
+	"go-boilerplate-api/pkg/health"
+	"time"
+
+	"google.golang.org/grpc"
+	grpcHealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// defaultHealthCheckInterval is used when the config does not specify a health check interval
+const defaultHealthCheckInterval = 10 * time.Second
+
+// registerHealth registers the standard grpc.health.v1 Health service on the server.
+// The serving status of the server ("") and of every service registered so far follows the readiness of the app.
+// The returned server is used to mark the services as not serving on shutdown
+func registerHealth(ctx context.Context, server *grpc.Server, appHealth *health.Health, interval time.Duration) *grpcHealth.Server {
+	healthServer := grpcHealth.NewServer()
+
+	services := []string{""}
+	for name := range server.GetServiceInfo() {
+		services = append(services, name)
+	}
+
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	go watchHealth(ctx, healthServer, appHealth, services, interval)
+
+	return healthServer
+}
+
+// watchHealth periodically runs the readiness checks and sets the serving status of the services.
+// It runs until the context is cancelled
+func watchHealth(ctx context.Context, healthServer *grpcHealth.Server, appHealth *health.Health, services []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if appHealth != nil && !appHealth.Ready(ctx).Ready {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+
+		for _, service := range services {
+			healthServer.SetServingStatus(service, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"go-boilerplate-api/shared"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 func registerService(server *grpc.Server, deps *shared.Deps) {
@@ -14,3 +15,13 @@ func registerService(server *grpc.Server, deps *shared.Deps) {
 	// Bind the RPC services to the grpc server
 	pb.RegisterUserServiceServer(server, userService)
 }
+
+// registerReflection registers the server reflection service when it is enabled for the tier.
+// It lets tools like grpcurl explore the services without the proto files
+func registerReflection(server *grpc.Server, deps *shared.Deps) {
+	if !deps.Config.Get().Server.GRPC.Reflection {
+		return
+	}
+
+	reflection.Register(server)
+}
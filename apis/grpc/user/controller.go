@@ -15,6 +15,10 @@ import (
 	"go-boilerplate-api/pkg/user/rating"
 	userRepo "go-boilerplate-api/pkg/user/repo"
 	pkgUtils "go-boilerplate-api/pkg/utils"
+	"go-boilerplate-api/pkg/utils/errdetails"
+	log "go-boilerplate-api/pkg/utils/logger"
+
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // Service contains the methods required to perfom operation's on users (proto definition)
@@ -32,17 +36,23 @@ func NewUserService(conf config.IConfig, db *db.Instances, apm apm.HandlerInterf
 	return &Service{user: userService}
 }
 
-// GetAll gets all users
+// GetAll gets a page of the users matching the filters of the request
 func (service *Service) GetAll(ctx context.Context, req *pb.UserGetRequest) (res *pb.Users, err error) {
-	defer utils.HandleError(&err)
+	defer utils.HandleError(ctx, &err)
+
+	query := user.ListQuery{}
+	err = pkgUtils.Bind(req, &query)
+	if err != nil {
+		return nil, err
+	}
 
-	users, err := service.user.GetAll()
+	users, err := service.user.List(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
 	res = &pb.Users{}
-	err = pkgUtils.Bind(users, &res.Users)
+	err = pkgUtils.Bind(users, &res)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +62,9 @@ func (service *Service) GetAll(ctx context.Context, req *pb.UserGetRequest) (res
 
 // GetOne gets one users
 func (service *Service) GetOne(ctx context.Context, req *pb.UserGetRequest) (res *pb.User, err error) {
-	defer utils.HandleError(&err)
+	// Scoped before the error handler is deferred so that the error it logs carries the user ID
+	ctx = log.NewContext(ctx, log.FromContext(ctx).WithField("userId", req.Id))
+	defer utils.HandleError(ctx, &err)
 
 	userReq := user.User{}
 	// Need to decode to user.User since User is an embedded struct
@@ -61,7 +73,7 @@ func (service *Service) GetOne(ctx context.Context, req *pb.UserGetRequest) (res
 		return nil, err
 	}
 
-	users, err := service.user.GetOne(userReq.ID)
+	users, err := service.user.GetOne(ctx, userReq.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -75,9 +87,9 @@ func (service *Service) GetOne(ctx context.Context, req *pb.UserGetRequest) (res
 	return res, nil
 }
 
-// Insert stores a user in the datastore
+// Insert stores a user in the datastore and returns it with the id assigned by the datastore
 func (service *Service) Insert(ctx context.Context, req *pb.User) (res *pb.User, err error) {
-	defer utils.HandleError(&err)
+	defer utils.HandleError(ctx, &err)
 
 	userReq := user.User{}
 	// Need to decode to user.User since User is an embedded struct
@@ -86,18 +98,25 @@ func (service *Service) Insert(ctx context.Context, req *pb.User) (res *pb.User,
 		return nil, err
 	}
 
-	err = service.user.Insert(userReq)
+	created, err := service.user.Insert(ctx, userReq)
+	if err != nil {
+		return nil, err
+	}
+
+	res = &pb.User{}
+	err = pkgUtils.Bind(created, &res)
 	if err != nil {
 		return nil, err
 	}
-	res = nil
 
 	return res, nil
 }
 
 // GetWithInfo gets a user from the database along with rating and favourites
 func (service *Service) GetWithInfo(ctx context.Context, req *pb.UserGetRequest) (res *pb.User, err error) {
-	defer utils.HandleError(&err)
+	// Scoped before the error handler is deferred so that the error it logs carries the user ID
+	ctx = log.NewContext(ctx, log.FromContext(ctx).WithField("userId", req.Id))
+	defer utils.HandleError(ctx, &err)
 
 	userReq := user.User{}
 	// Need to decode to user.User since User is an embedded struct
@@ -106,7 +125,7 @@ func (service *Service) GetWithInfo(ctx context.Context, req *pb.UserGetRequest)
 		return nil, err
 	}
 
-	users, err := service.user.GetWithInfo(userReq.ID)
+	users, err := service.user.GetWithInfo(ctx, userReq.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -119,3 +138,111 @@ func (service *Service) GetWithInfo(ctx context.Context, req *pb.UserGetRequest)
 
 	return res, nil
 }
+
+// UpdateUser updates the fields of the update mask, or the fields set if there is no mask, and returns the updated user
+func (service *Service) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (res *pb.User, err error) {
+	// Scoped before the error handler is deferred so that the error it logs carries the user ID
+	ctx = log.NewContext(ctx, log.FromContext(ctx).WithField("userId", req.GetUser().GetId()))
+	defer utils.HandleError(ctx, &err)
+
+	if req.GetUser().GetId() == "" {
+		return nil, errdetails.InvalidField("APIS.GRPC.USER.ID_REQUIRED", "user.id", "The id of the user is required")
+	}
+
+	patch, err := maskPatch(req.GetUser(), req.GetUpdateMask())
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := service.user.Patch(ctx, req.GetUser().GetId(), patch)
+	if err != nil {
+		return nil, err
+	}
+
+	res = &pb.User{}
+	err = pkgUtils.Bind(updated, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DeleteUser deletes a user from the datastore
+func (service *Service) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (res *emptypb.Empty, err error) {
+	// Scoped before the error handler is deferred so that the error it logs carries the user ID
+	ctx = log.NewContext(ctx, log.FromContext(ctx).WithField("userId", req.GetId()))
+	defer utils.HandleError(ctx, &err)
+
+	err = service.user.Delete(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListUsers gets a page of the users matching the filters of the request along with the token of the next page
+func (service *Service) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (res *pb.ListUsersResponse, err error) {
+	defer utils.HandleError(ctx, &err)
+
+	query := user.ListQuery{}
+	err = pkgUtils.Bind(req, &query)
+	if err != nil {
+		return nil, err
+	}
+	query.Limit = int(req.GetPageSize())
+	query.Cursor = req.GetPageToken()
+
+	users, err := service.user.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	res = &pb.ListUsersResponse{NextPageToken: users.NextCursor, TotalSize: int32(users.Total)}
+	err = pkgUtils.Bind(users.Users, &res.Users)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// BatchGetUsers gets the users in the order of the ids, it fails with NotFound if any of them does not exist
+func (service *Service) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (res *pb.BatchGetUsersResponse, err error) {
+	defer utils.HandleError(ctx, &err)
+
+	users, err := service.user.BatchGet(ctx, req.GetIds())
+	if err != nil {
+		return nil, err
+	}
+
+	res = &pb.BatchGetUsersResponse{}
+	err = pkgUtils.Bind(users, &res.Users)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ExportUsers streams all the users matching the filters of the request, they are read from the datastore one page at a time
+func (service *Service) ExportUsers(req *pb.ExportUsersRequest, stream pb.UserService_ExportUsersServer) (err error) {
+	ctx := stream.Context()
+	defer utils.HandleError(ctx, &err)
+
+	query := user.ListQuery{}
+	err = pkgUtils.Bind(req, &query)
+	if err != nil {
+		return err
+	}
+
+	return service.user.Export(ctx, query, func(u *user.User) error {
+		res := &pb.User{}
+		err := pkgUtils.Bind(u, &res)
+		if err != nil {
+			return err
+		}
+		return stream.Send(res)
+	})
+}
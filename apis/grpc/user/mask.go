@@ -0,0 +1,41 @@
+package user
+
+import (
+	"encoding/json"
+
+	pb "go-boilerplate-api/apis/grpc/generated/user"
+	"go-boilerplate-api/pkg/utils/errdetails"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// maskFields are the paths of the update mask with the values of the user they set
+var maskFields = map[string]func(u *pb.User) interface{}{
+	"name": func(u *pb.User) interface{} { return u.GetName() },
+}
+
+// maskPatch converts the fields of the user in the mask to the JSON Merge Patch updating them.
+// The fields set in the user are updated if there is no mask
+func maskPatch(u *pb.User, mask *fieldmaskpb.FieldMask) ([]byte, error) {
+	paths := mask.GetPaths()
+	if len(paths) == 0 {
+		for path, value := range maskFields {
+			if value(u) != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	patch := map[string]interface{}{}
+	for _, path := range paths {
+		value, ok := maskFields[path]
+		if !ok {
+			return nil, errdetails.InvalidField("APIS.GRPC.USER.INVALID_UPDATE_MASK", "update_mask.paths", "The field "+path+" of the update mask can not be updated")
+		}
+		patch[path] = value(u)
+	}
+
+	// Marshalling the map of the strings does not fail
+	bytes, _ := json.Marshal(patch)
+	return bytes, nil
+}
@@ -0,0 +1,66 @@
+package user
+
+import (
+	"os"
+	"testing"
+
+	pb "go-boilerplate-api/apis/grpc/generated/user"
+	"go-boilerplate-api/pkg/utils/errdetails"
+
+	"github.com/ralstan-vaz/go-errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+func TestMaskPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		user  *pb.User
+		mask  *fieldmaskpb.FieldMask
+		patch string
+	}{
+		{name: "field in the mask", user: &pb.User{Id: "1", Name: "Garrus"}, mask: &fieldmaskpb.FieldMask{Paths: []string{"name"}}, patch: `{"name":"Garrus"}`},
+		// An empty value in the mask clears the field
+		{name: "empty field in the mask", user: &pb.User{Id: "1"}, mask: &fieldmaskpb.FieldMask{Paths: []string{"name"}}, patch: `{"name":""}`},
+		{name: "nil mask updates the fields set", user: &pb.User{Id: "1", Name: "Garrus"}, mask: nil, patch: `{"name":"Garrus"}`},
+		{name: "empty mask updates the fields set", user: &pb.User{Id: "1", Name: "Garrus"}, mask: &fieldmaskpb.FieldMask{}, patch: `{"name":"Garrus"}`},
+		{name: "empty mask without fields set", user: &pb.User{Id: "1"}, mask: &fieldmaskpb.FieldMask{}, patch: `{}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patch, err := maskPatch(test.user, test.mask)
+			assert.Nil(t, err)
+			assert.JSONEq(t, test.patch, string(patch))
+		})
+	}
+}
+
+func TestMaskPatchUnknownPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{name: "unknown path", paths: []string{"email"}},
+		{name: "id can not be updated", paths: []string{"id"}},
+		{name: "unknown path next to a known one", paths: []string{"name", "rating"}},
+		{name: "path in another case", paths: []string{"Name"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := maskPatch(&pb.User{Id: "1", Name: "Garrus"}, &fieldmaskpb.FieldMask{Paths: test.paths})
+			assert.True(t, errors.IsBadRequest(err))
+			assert.Equal(t, "APIS.GRPC.USER.INVALID_UPDATE_MASK", errors.Get(err).Code)
+			if d := errdetails.Get(err); assert.NotNil(t, d) && assert.Len(t, d.Violations, 1) {
+				assert.Equal(t, "update_mask.paths", d.Violations[0].Field)
+			}
+		})
+	}
+}
@@ -1,15 +1,22 @@
 package utils
 
 import (
+	"context"
+	"go-boilerplate-api/pkg/utils/errdetails"
+	"go-boilerplate-api/pkg/utils/errdetails/grpcstatus"
 	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/redact"
 
 	"github.com/ralstan-vaz/go-errors"
-	"github.com/ralstan-vaz/go-errors/grpc"
-	"google.golang.org/grpc/status"
 )
 
-// HandleError formats, logs and sets a GRPC response for the error
-func HandleError(errObj *error) error {
+// ErrorDomain is the domain of the ErrorInfo sent along with the errors
+const ErrorDomain = "go-boilerplate-api"
+
+// HandleError formats, logs and sets a GRPC response for the error, the log carries the request ID of the context.
+// The error is replaced by a status carrying its code and details (see grpcstatus.ToStatus), so that the handlers deferring it return the status.
+// The description, the message and the details sent to the client are redacted by the redaction policy
+func HandleError(ctx context.Context, errObj *error) error {
 	if *errObj == nil {
 		return nil
 	}
@@ -20,9 +27,16 @@ func HandleError(errObj *error) error {
 		err.Message = "Something Went Wrong"
 	}
 
-	log.Error(err.Code, err.Description, log.Priority1, err.Source)
+	log.FromContext(ctx).Error(err.Code, err.Description, log.Priority1, err.Source)
 
-	statusCode := grpc.StatusCode(err)
+	policy := redact.Current()
+	sent := *err
+	sent.Description = policy.String(err.Description)
+	sent.Message = policy.String(err.Message)
+	if d := errdetails.Get(err); d != nil {
+		sent.Wrap(d.Redacted(policy))
+	}
 
-	return status.New(statusCode, err.Description).Err()
+	*errObj = grpcstatus.ToStatus(&sent, ErrorDomain).Err()
+	return *errObj
 }
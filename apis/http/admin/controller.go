@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"net/http"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"go-boilerplate-api/apis/http/utils"
+	"go-boilerplate-api/apis/routes"
+	"go-boilerplate-api/config"
+	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/shared"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ralstan-vaz/go-errors"
+	"gopkg.in/yaml.v3"
+)
+
+// overrideRequest is the body of the request changing the log levels
+type overrideRequest struct {
+	Level    string            `json:"level"`
+	Packages map[string]string `json:"packages"`
+	// TTL is how long the levels are kept eg. 15m, log.overrideTTL of the config by default
+	TTL string `json:"ttl"`
+}
+
+// routesResponse is the response of the routes served by the app
+type routesResponse struct {
+	Routes []routes.Route `json:"routes"`
+}
+
+// Service contains the handlers of the admin routes
+type Service struct {
+	deps *shared.Deps
+	conf config.IConfig
+
+	// The routes are listed on the first request as they do not change while the app runs
+	routesOnce sync.Once
+	routeList  []routes.Route
+}
+
+// NewAdminService creates a new instance of a Service with the given dependencies
+func NewAdminService(deps *shared.Deps) *Service {
+	return &Service{deps: deps, conf: deps.Config}
+}
+
+// config returns the effective config in yaml, the same way as the config print command, with the sensitive values masked
+func (service *Service) config(ctx *gin.Context) {
+	var err error
+	defer utils.HandleError(ctx, &err)
+
+	bytes, err := yaml.Marshal(config.Masked(service.conf.Get()))
+	if err != nil {
+		err = errors.NewInternalError(err).SetCode("APIS.HTTP.ADMIN.CONFIG_MARSHAL_FAILED")
+		return
+	}
+	ctx.Data(http.StatusOK, "application/yaml; charset=utf-8", bytes)
+}
+
+// build returns the version of the app along with the build info
+func (service *Service) build(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, shared.BuildInfo())
+}
+
+// routes returns the http routes and the grpc methods served by the app
+func (service *Service) routes(ctx *gin.Context) {
+	service.routesOnce.Do(func() {
+		service.routeList = routes.List(service.deps)
+	})
+	ctx.JSON(http.StatusOK, routesResponse{Routes: service.routeList})
+}
+
+// goroutines returns the stack traces of all the goroutines in the format of an unrecovered panic
+func (service *Service) goroutines(ctx *gin.Context) {
+	ctx.Header("Content-Type", "text/plain; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	pprof.Lookup("goroutine").WriteTo(ctx.Writer, 2)
+}
+
+// logLevels returns the log levels in effect
+func (service *Service) logLevels(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, log.CurrentLevels())
+}
+
+// overrideLogLevels changes the log levels until the ttl expires
+func (service *Service) overrideLogLevels(ctx *gin.Context) {
+	var err error
+	defer utils.HandleError(ctx, &err)
+
+	var req overrideRequest
+	if err = ctx.ShouldBindJSON(&req); err != nil {
+		err = errors.NewBadRequest("Could not bind request to model").SetCode("APIS.HTTP.ADMIN.REQUEST_BIND_FAILED")
+		return
+	}
+
+	ttl := service.conf.Get().Log.OverrideTTL
+	if req.TTL != "" {
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil {
+			err = errors.NewBadRequest("Invalid ttl " + req.TTL).SetCode("APIS.HTTP.ADMIN.INVALID_TTL")
+			return
+		}
+	}
+
+	err = log.OverrideLevels(log.Levels{Level: req.Level, Packages: req.Packages}, ttl)
+	if err != nil {
+		return
+	}
+
+	log.FromContext(ctx.Request.Context()).Warn("Log levels overridden", map[string]interface{}{"level": req.Level, "packages": req.Packages, "ttl": ttl.String()})
+	ctx.JSON(http.StatusOK, log.CurrentLevels())
+}
+
+// revertLogLevels reverts the log levels to the configured ones
+func (service *Service) revertLogLevels(ctx *gin.Context) {
+	log.RevertLevels()
+	ctx.JSON(http.StatusOK, log.CurrentLevels())
+}
@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pprofHandler serves the net/http/pprof handlers under /debug/pprof/.
+// Gin does not allow the named routes along with the wildcard, so the handler is picked from the path
+func pprofHandler(ctx *gin.Context) {
+	switch strings.Trim(ctx.Param("profile"), "/") {
+	case "cmdline":
+		pprof.Cmdline(ctx.Writer, ctx.Request)
+	case "profile":
+		pprof.Profile(ctx.Writer, ctx.Request)
+	case "symbol":
+		pprof.Symbol(ctx.Writer, ctx.Request)
+	case "trace":
+		pprof.Trace(ctx.Writer, ctx.Request)
+	default:
+		// Index serves the other profiles eg. heap, goroutine by the name in the path
+		pprof.Index(ctx.Writer, ctx.Request)
+	}
+}
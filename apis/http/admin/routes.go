@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"expvar"
+	"go-boilerplate-api/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewAdminRoute Creates and initializes the admin routes
+func NewAdminRoute(router *gin.Engine, deps *shared.Deps) {
+	bindRoutes(router, deps)
+}
+
+func bindRoutes(router *gin.Engine, deps *shared.Deps) {
+	service := NewAdminService(deps)
+
+	router.GET("/config", service.config)
+	router.GET("/build", service.build)
+	router.GET("/routes", service.routes)
+
+	logAPI := router.Group("/log")
+	{
+		logAPI.GET("/levels", service.logLevels)
+		logAPI.PUT("/levels", service.overrideLogLevels)
+		logAPI.DELETE("/levels", service.revertLogLevels)
+	}
+
+	debugAPI := router.Group("/debug")
+	{
+		debugAPI.GET("/goroutines", service.goroutines)
+		debugAPI.GET("/vars", gin.WrapH(expvar.Handler()))
+		debugAPI.GET("/pprof/*profile", pprofHandler)
+		// The symbols are looked up with a POST by go tool pprof
+		debugAPI.POST("/pprof/*profile", pprofHandler)
+	}
+}
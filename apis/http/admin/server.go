@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go-boilerplate-api/apis/http/debug"
+	"go-boilerplate-api/apis/http/utils"
+	"go-boilerplate-api/apis/middleware"
+	"go-boilerplate-api/config"
+	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/shared"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// Server wraps the admin http server so that it can be drained on shutdown
+type Server struct {
+	server *http.Server
+}
+
+// StartServer starts the admin server on server.admin.address, a listener of its own
+// so that the diagnostics are never served by the public http server.
+// The server runs in its own goroutine, the returned Server is used to shut it down
+func StartServer(deps *shared.Deps, wg *sync.WaitGroup, fatalError chan error) *Server {
+	address := deps.Config.Get().Server.Admin.Address
+
+	server := &http.Server{
+		Addr:    address,
+		Handler: NewRouter(deps),
+	}
+
+	go func() {
+		// Go routine finished
+		defer wg.Done()
+
+		log.Debug("Admin Server listening on : " + address)
+
+		// Start the server, ErrServerClosed is returned once Shutdown is called
+		err := server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			fatalError <- errors.NewInternalError(err).SetCode("APIS.HTTP.ADMIN.LISTENER_FAILED")
+		}
+	}()
+
+	return &Server{server: server}
+}
+
+// NewRouter creates the gin router of the admin server with the middlewares and the admin routes initialized
+func NewRouter(deps *shared.Deps) *gin.Engine {
+	router := gin.New()
+	// Identifies the request so that the logs of the admin routes carry the request ID
+	router.Use(middleware.RequestIDMiddleware())
+	// Adds panic handler as a middleware
+	router.Use(middleware.HandlePanic(deps.Apm, deps.Config))
+	// Rejects the requests without the token, if one is configured
+	router.Use(authenticate(deps.Config))
+
+	// Initializes the admin routes
+	NewAdminRoute(router, deps)
+	// Initializes the debug routes of the recording apm handler
+	debug.NewDebugRoute(router, deps)
+
+	return router
+}
+
+// Shutdown stops accepting new connections and waits for the in-flight requests to complete.
+// If the context expires before the requests complete the remaining connections are closed
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		s.server.Close()
+		return errors.NewInternalError(err).SetCode("APIS.HTTP.ADMIN.SHUTDOWN_FAILED")
+	}
+
+	return nil
+}
+
+// authenticate rejects the requests that do not carry server.admin.token as a bearer token.
+// The token is read on every request so that a change in it applies without a restart
+func authenticate(conf config.IConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := conf.Get().Server.Admin.Token
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		sent := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) == 1 {
+			c.Next()
+			return
+		}
+
+		var err error = errors.NewUnauthorized("The admin token is missing or invalid").SetCode("APIS.HTTP.ADMIN.UNAUTHORIZED")
+		utils.HandleError(c, &err)
+		c.Abort()
+	}
+}
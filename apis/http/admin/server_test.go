@@ -0,0 +1,140 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	publicHTTP "go-boilerplate-api/apis/http"
+	"go-boilerplate-api/apm"
+	"go-boilerplate-api/config"
+	"go-boilerplate-api/pkg/clients/db"
+	"go-boilerplate-api/shared"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	t := m.Run()
+	os.Exit(t)
+}
+
+// staticConfig is an IConfig that always returns the same config
+type staticConfig struct {
+	conf *config.Config
+}
+
+func (s *staticConfig) Get() *config.Config                        { return s.conf }
+func (s *staticConfig) Subscribe(fn func(old, new *config.Config)) {}
+func (s *staticConfig) Close() error                               { return nil }
+
+const testToken = "admin-token"
+
+// newTestDeps returns the dependencies of the routers with the admin token configured.
+// The routers are only built, so the database is never connected
+func newTestDeps() *shared.Deps {
+	conf := &config.Config{}
+	conf.Server.Admin.Token = testToken
+	return &shared.Deps{Config: &staticConfig{conf: conf}, Database: &db.Instances{}, Apm: apm.NewNoopHandler()}
+}
+
+// serve sends a request to the router and returns the recorded response
+func serve(router http.Handler, method string, path string, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	return res
+}
+
+func TestAuthenticate(t *testing.T) {
+	router := NewRouter(newTestDeps())
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{name: "missing token", token: "", status: http.StatusUnauthorized},
+		{name: "wrong token", token: "not-the-token", status: http.StatusUnauthorized},
+		{name: "valid token", token: testToken, status: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := serve(router, http.MethodGet, "/build", test.token)
+			assert.Equal(t, test.status, res.Code)
+		})
+	}
+}
+
+func TestAuthenticateWithoutToken(t *testing.T) {
+	deps := newTestDeps()
+	deps.Config.Get().Server.Admin.Token = ""
+
+	// The requests are not authenticated when no token is configured
+	res := serve(NewRouter(deps), http.MethodGet, "/build", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestConfigMasksSecrets(t *testing.T) {
+	deps := newTestDeps()
+	deps.Config.Get().Server.Admin.Address = "127.0.0.1:6060"
+
+	res := serve(NewRouter(deps), http.MethodGet, "/config", testToken)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.True(t, strings.HasPrefix(res.Header().Get("Content-Type"), "application/yaml"))
+
+	body := res.Body.String()
+	assert.NotContains(t, body, testToken)
+	assert.Contains(t, body, "token: '****'")
+	// The values that are not sensitive are returned as is
+	assert.Contains(t, body, "127.0.0.1:6060")
+}
+
+func TestDiagnostics(t *testing.T) {
+	router := NewRouter(newTestDeps())
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		contains string
+	}{
+		{name: "pprof index", method: http.MethodGet, path: "/debug/pprof/", contains: "goroutine"},
+		{name: "pprof profile", method: http.MethodGet, path: "/debug/pprof/goroutine?debug=1", contains: "goroutine profile"},
+		{name: "pprof symbol", method: http.MethodPost, path: "/debug/pprof/symbol", contains: "num_symbols"},
+		{name: "goroutines", method: http.MethodGet, path: "/debug/goroutines", contains: "goroutine"},
+		{name: "vars", method: http.MethodGet, path: "/debug/vars", contains: "memstats"},
+		{name: "routes", method: http.MethodGet, path: "/routes", contains: "/proto.UserService/"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := serve(router, test.method, test.path, testToken)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Contains(t, res.Body.String(), test.contains)
+
+			// The diagnostics are not served without the token
+			res = serve(router, test.method, test.path, "")
+			assert.Equal(t, http.StatusUnauthorized, res.Code)
+		})
+	}
+}
+
+func TestPprofNotServedByPublicRouter(t *testing.T) {
+	deps := newTestDeps()
+
+	res := serve(NewRouter(deps), http.MethodGet, "/debug/pprof/", testToken)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	res = serve(publicHTTP.NewRouter(deps), http.MethodGet, "/debug/pprof/", testToken)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
@@ -0,0 +1,34 @@
+package debug
+
+import (
+	"go-boilerplate-api/apm"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transactionsResponse is the response of the recorded apm transactions
+type transactionsResponse struct {
+	Transactions []apm.RecordedTransaction `json:"transactions"`
+}
+
+// Service contains the handlers of the debug routes
+type Service struct {
+	recorder *apm.RecordingHandler
+}
+
+// NewDebugService creates a new instance of a Service with the given dependencies
+func NewDebugService(recorder *apm.RecordingHandler) *Service {
+	return &Service{recorder: recorder}
+}
+
+// transactions returns a response for the /debug/apm request with the recorded transactions, the oldest first
+func (service *Service) transactions(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, transactionsResponse{Transactions: service.recorder.Transactions()})
+}
+
+// reset removes the recorded transactions
+func (service *Service) reset(ctx *gin.Context) {
+	service.recorder.Reset()
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,28 @@
+package debug
+
+import (
+	"go-boilerplate-api/apm"
+	"go-boilerplate-api/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewDebugRoute Creates and initializes the debug routes.
+// The apm routes are registered only when the recording apm handler is in use
+func NewDebugRoute(router *gin.Engine, deps *shared.Deps) {
+	bindRoutes(router, deps)
+}
+
+func bindRoutes(router *gin.Engine, deps *shared.Deps) {
+	recorder, ok := deps.Apm.(*apm.RecordingHandler)
+	if !ok {
+		return
+	}
+
+	service := NewDebugService(recorder)
+	debugAPI := router.Group("/debug")
+	{
+		debugAPI.GET("/apm", service.transactions)
+		debugAPI.DELETE("/apm", service.reset)
+	}
+}
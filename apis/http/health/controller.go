@@ -0,0 +1,45 @@
+package health
+
+import (
+	"go-boilerplate-api/pkg/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// liveResponse is the response of the liveness probe
+type liveResponse struct {
+	Status string `json:"status"`
+}
+
+// aliveStatus is sent as long as the process is able to serve requests
+const aliveStatus string = "alive"
+
+// Service contains the handlers for the liveness and readiness probes
+type Service struct {
+	health *health.Health
+}
+
+// NewHealthService creates a new instance of a Service with the given dependencies
+func NewHealthService(appHealth *health.Health) *Service {
+	return &Service{health: appHealth}
+}
+
+// live returns a response for the /health/live request.
+// It does not check the dependencies so that a broken dependency does not restart the app
+func (service *Service) live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, liveResponse{Status: aliveStatus})
+}
+
+// ready returns a response for the /health/ready request with the status and latency of every dependency.
+// Responds with 503 when a dependency is down or the app is draining
+func (service *Service) ready(ctx *gin.Context) {
+	report := service.health.Ready(ctx.Request.Context())
+
+	statusCode := http.StatusOK
+	if !report.Ready {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	ctx.JSON(statusCode, report)
+}
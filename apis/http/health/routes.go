@@ -0,0 +1,21 @@
+package health
+
+import (
+	"go-boilerplate-api/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewHealthRoute Creates and initializes the liveness and readiness routes
+func NewHealthRoute(router *gin.Engine, deps *shared.Deps) {
+	bindRoutes(router, deps)
+}
+
+func bindRoutes(router *gin.Engine, deps *shared.Deps) {
+	service := NewHealthService(deps.Health)
+	healthAPI := router.Group("/health")
+	{
+		healthAPI.GET("/live", service.live)
+		healthAPI.GET("/ready", service.ready)
+	}
+}
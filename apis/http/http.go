@@ -1,45 +1,143 @@
 package http
 
 import (
+	"context"
+	"net/http"
+	"strconv"
 	"sync"
 
+	"go-boilerplate-api/apis/http/health"
+	"go-boilerplate-api/apis/http/metrics"
 	"go-boilerplate-api/apis/http/ping"
 	httpUser "go-boilerplate-api/apis/http/user"
 	"go-boilerplate-api/apis/middleware"
-	"go-boilerplate-api/apm"
 	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/tlsconfig"
 	"go-boilerplate-api/shared"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ralstan-vaz/go-errors"
 )
 
+// Server wraps the http server so that it can be drained on shutdown
+type Server struct {
+	server *http.Server
+	// reloader reloads the certificates of the server, nil when TLS is disabled
+	reloader *tlsconfig.Reloader
+}
+
 // StartServer starts the http server using the dependencies passed to it.
-// It also initializes the routes
-func StartServer(deps *shared.Deps, wg *sync.WaitGroup, fatalError chan error) error {
-	address := deps.Config.Get().Server.HTTP.Address
+// It also initializes the routes.
+// The server runs in its own goroutine, the returned Server is used to shut it down
+func StartServer(deps *shared.Deps, wg *sync.WaitGroup, fatalError chan error) *Server {
+	httpConf := deps.Config.Get().Server.HTTP
+	address := httpConf.Address
+
+	gin.SetMode(ginMode(httpConf.Mode))
+	router := NewRouter(deps)
+
+	server := &http.Server{
+		Addr:         address,
+		Handler:      router,
+		ReadTimeout:  httpConf.ReadTimeout,
+		WriteTimeout: httpConf.WriteTimeout,
+		IdleTimeout:  httpConf.IdleTimeout,
+	}
+
+	// The certificates are loaded before the server starts, a failure is reported like a listener failure
+	var reloader *tlsconfig.Reloader
+	var tlsErr error
+	if httpConf.TLS.Enabled {
+		server.TLSConfig, reloader, tlsErr = tlsconfig.NewServerConfig(httpConf.TLS.Options(), deps.Config.Get().Reload.Interval)
+	}
+
+	go func() {
+		// Go routine finished
+		defer wg.Done()
+
+		if tlsErr != nil {
+			fatalError <- tlsErr
+			return
+		}
+
+		log.Debug("HTTP Server listening on : " + address + ", TLS: " + strconv.FormatBool(httpConf.TLS.Enabled) + ", Version: " + shared.VERSION)
+
+		// Start the server, ErrServerClosed is returned once Shutdown is called
+		var err error
+		if server.TLSConfig != nil {
+			// The certificate is served by the TLS config, so no files are passed
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			fatalError <- errors.NewInternalError(err).SetCode("APIS.HTTP.LISTENER_FAILED")
+		}
+	}()
 
-	gin.SetMode(gin.DebugMode) // ToDo chage to release mode before prod
-	router := gin.Default()
+	return &Server{server: server, reloader: reloader}
+}
+
+// NewRouter creates the gin router with the middlewares and all the routes initialized
+func NewRouter(deps *shared.Deps) *gin.Engine {
+	httpConf := deps.Config.Get().Server.HTTP
+
+	// The requests are logged by the access log middleware rather than the text logger of gin
+	// and the panics are recovered by HandlePanic
+	router := gin.New()
+	router.RedirectTrailingSlash = httpConf.RedirectTrailingSlash
+	router.RedirectFixedPath = httpConf.RedirectFixedPath
+	router.MaxMultipartMemory = httpConf.MaxMultipartMemory
+	// The forwarded client IP is only read from the trusted proxies
+	router.ForwardedByClientIP = len(httpConf.TrustedProxies) > 0
+	if router.ForwardedByClientIP {
+		router.Use(middleware.TrustedProxiesMiddleware(httpConf.TrustedProxies))
+	}
+	// Records the RED metrics of the requests, first so that the latency covers the other middlewares
+	router.Use(middleware.MetricsMiddleware(deps.Metrics))
+	// Identifies the request, before the other middlewares so that their logs carry the request ID
+	router.Use(middleware.RequestIDMiddleware())
+	// Stores the identity of the client authenticated with its certificate, if any
+	router.Use(middleware.ClientIdentityMiddleware())
+	// Writes the access logs, after the request ID so that they carry it
+	router.Use(middleware.AccessLogMiddleware(deps.Config))
 	// Injects apm to trace http requests in gin
-	router.Use(middleware.ApmMiddleware(apm.APM))
+	router.Use(middleware.ApmMiddleware(deps.Apm))
 	// Adds panic handler as a middleware
-	router.Use(middleware.HandlePanic)
+	router.Use(middleware.HandlePanic(deps.Apm, deps.Config))
 
 	// Initializes Ping routes
 	ping.NewPingRoute(router)
+	// Initializes liveness and readiness routes
+	health.NewHealthRoute(router, deps)
+	// Initializes the Prometheus metrics route
+	metrics.NewMetricsRoute(router, deps)
 	// Initialize all the routes
 	httpUser.NewUserRoute(router, deps)
 
-	log.Debug("HTTP Server listening on : " + address + ", Version: " + shared.VERSION)
+	return router
+}
 
-	// Start the server
-	err := router.Run(address)
-	if err != nil {
-		fatalError <- err
+// ginMode returns the mode of gin, release unless the config sets another one
+func ginMode(mode string) string {
+	if mode == "" {
+		return gin.ReleaseMode
+	}
+	return mode
+}
+
+// Shutdown stops accepting new connections and waits for the in-flight requests to complete.
+// If the context expires before the requests complete the remaining connections are closed
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.reloader != nil {
+		defer s.reloader.Close()
 	}
 
-	// Go routine finished (can also be deferred)
-	wg.Done()
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		s.server.Close()
+		return errors.NewInternalError(err).SetCode("APIS.HTTP.SHUTDOWN_FAILED")
+	}
 
 	return nil
 }
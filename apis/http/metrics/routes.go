@@ -0,0 +1,16 @@
+package metrics
+
+import (
+	"go-boilerplate-api/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewMetricsRoute Creates and initializes the route serving the metrics in the Prometheus text format
+func NewMetricsRoute(router *gin.Engine, deps *shared.Deps) {
+	bindRoutes(router, deps)
+}
+
+func bindRoutes(router *gin.Engine, deps *shared.Deps) {
+	router.GET("/metrics", gin.WrapH(deps.Metrics.Handler()))
+}
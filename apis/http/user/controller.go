@@ -1,6 +1,8 @@
 package user
 
 import (
+	"io/ioutil"
+	"mime"
 	"net/http"
 
 	"go-boilerplate-api/apis/http/utils"
@@ -13,6 +15,8 @@ import (
 	"go-boilerplate-api/pkg/user/favourite"
 	"go-boilerplate-api/pkg/user/rating"
 	userRepo "go-boilerplate-api/pkg/user/repo"
+	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/mergepatch"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ralstan-vaz/go-errors"
@@ -32,11 +36,17 @@ func NewUserService(conf config.IConfig, db *db.Instances, apm apm.HandlerInterf
 	return &Service{user: userService}
 }
 
-func (service *Service) getAll(ctx *gin.Context) {
+// list gets a page of the users matching the filters of the query parameters
+func (service *Service) list(ctx *gin.Context) {
 	var err error
 	defer utils.HandleError(ctx, &err)
 
-	users, err := service.user.GetAll()
+	query, err := bindListQuery(ctx.Request.URL.Query())
+	if err != nil {
+		return
+	}
+
+	users, err := service.user.List(ctx.Request.Context(), query)
 	if err != nil {
 		return
 	}
@@ -48,8 +58,9 @@ func (service *Service) getOne(ctx *gin.Context) {
 	var err error
 	defer utils.HandleError(ctx, &err)
 
-	userID := ctx.Param("userID")
-	users, err := service.user.GetOne(userID)
+	userID := ctx.Param("userId")
+	utils.WithLogFields(ctx, log.Fields{"userId": userID})
+	users, err := service.user.GetOne(ctx.Request.Context(), userID)
 	if err != nil {
 		return
 	}
@@ -61,8 +72,9 @@ func (service *Service) getWithInfo(ctx *gin.Context) {
 	var err error
 	defer utils.HandleError(ctx, &err)
 
-	userID := ctx.Param("userID")
-	users, err := service.user.GetWithInfo(userID)
+	userID := ctx.Param("userId")
+	utils.WithLogFields(ctx, log.Fields{"userId": userID})
+	users, err := service.user.GetWithInfo(ctx.Request.Context(), userID)
 	if err != nil {
 		return
 	}
@@ -80,10 +92,77 @@ func (service *Service) insert(ctx *gin.Context) {
 		return
 	}
 
-	err = service.user.Insert(user)
+	created, err := service.user.Insert(ctx.Request.Context(), user)
+	if err != nil {
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (service *Service) update(ctx *gin.Context) {
+	var err error
+	defer utils.HandleError(ctx, &err)
+
+	userID := ctx.Param("userId")
+	utils.WithLogFields(ctx, log.Fields{"userId": userID})
+
+	var user user.User
+	if err = ctx.ShouldBindJSON(&user); err != nil {
+		err = errors.NewBadRequest("Could not bind request to model").SetCode("APIS.HTTP.USER.REQUEST_BIND_FAILD")
+		return
+	}
+
+	updated, err := service.user.Update(ctx.Request.Context(), userID, user)
+	if err != nil {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+// patch applies the JSON Merge Patch of the body, sent as application/merge-patch+json or application/json
+func (service *Service) patch(ctx *gin.Context) {
+	var err error
+	defer utils.HandleError(ctx, &err)
+
+	userID := ctx.Param("userId")
+	utils.WithLogFields(ctx, log.Fields{"userId": userID})
+
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	if mediaType != mergepatch.ContentType && mediaType != gin.MIMEJSON {
+		err = errors.New(errors.Error{
+			Kind:        utils.UnsupportedMediaType,
+			Code:        "APIS.HTTP.USER.UNSUPPORTED_PATCH",
+			Description: "The patch must be sent as " + mergepatch.ContentType,
+		})
+		return
+	}
+
+	patch, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		err = errors.NewBadRequest("Could not read the request body").SetCode("APIS.HTTP.USER.REQUEST_READ_FAILED")
+		return
+	}
+
+	updated, err := service.user.Patch(ctx.Request.Context(), userID, patch)
+	if err != nil {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+func (service *Service) delete(ctx *gin.Context) {
+	var err error
+	defer utils.HandleError(ctx, &err)
+
+	userID := ctx.Param("userId")
+	utils.WithLogFields(ctx, log.Fields{"userId": userID})
+	err = service.user.Delete(ctx.Request.Context(), userID)
 	if err != nil {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, nil)
+	ctx.Status(http.StatusNoContent)
 }
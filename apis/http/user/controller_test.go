@@ -0,0 +1,66 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-boilerplate-api/apm"
+	"go-boilerplate-api/config"
+	"go-boilerplate-api/pkg/clients/db"
+	"go-boilerplate-api/shared"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// staticConfig is an IConfig that always returns the same config
+type staticConfig struct {
+	conf *config.Config
+}
+
+func (s *staticConfig) Get() *config.Config                        { return s.conf }
+func (s *staticConfig) Subscribe(fn func(old, new *config.Config)) {}
+func (s *staticConfig) Close() error                               { return nil }
+
+// newTestRouter returns a router serving the user routes from the in-memory database
+func newTestRouter() *gin.Engine {
+	deps := &shared.Deps{Config: &staticConfig{conf: &config.Config{}}, Database: &db.Instances{MyDB: db.NewMyDB()}, Apm: apm.NewNoopHandler()}
+	router := gin.New()
+	NewUserRoute(router, deps)
+	return router
+}
+
+func TestPatchContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		status      int
+	}{
+		{name: "merge patch", contentType: "application/merge-patch+json", status: http.StatusOK},
+		{name: "merge patch with a charset", contentType: "application/merge-patch+json; charset=utf-8", status: http.StatusOK},
+		{name: "json", contentType: "application/json", status: http.StatusOK},
+		{name: "json patch", contentType: "application/json-patch+json", status: http.StatusUnsupportedMediaType},
+		{name: "text", contentType: "text/plain", status: http.StatusUnsupportedMediaType},
+		{name: "missing", contentType: "", status: http.StatusUnsupportedMediaType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`{"name":"Garrus"}`))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			res := httptest.NewRecorder()
+			newTestRouter().ServeHTTP(res, req)
+
+			assert.Equal(t, test.status, res.Code)
+			if test.status == http.StatusOK {
+				assert.Contains(t, res.Body.String(), `"name":"Garrus"`)
+				return
+			}
+			assert.Contains(t, res.Body.String(), "application/merge-patch+json")
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package user
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	user "go-boilerplate-api/pkg/user"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// Parameters of the list query that are not filters
+const (
+	sortParam   = "sort"
+	limitParam  = "limit"
+	cursorParam = "cursor"
+)
+
+// bindListQuery binds the query parameters to a list query.
+// A field parameter filters by its value, eg. name=Shepard, and an operator can be set in brackets eg. name[prefix]=She.
+// sort is a comma separated list of fields, prefixed with - for the descending order eg. sort=-name,id
+func bindListQuery(params url.Values) (user.ListQuery, error) {
+	q := user.ListQuery{Cursor: params.Get(cursorParam)}
+
+	if limit := params.Get(limitParam); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return q, errors.NewBadRequest("The limit must be a number").SetCode("APIS.HTTP.USER.INVALID_LIMIT")
+		}
+		q.Limit = value
+	}
+
+	if sorts := params.Get(sortParam); sorts != "" {
+		for _, field := range strings.Split(sorts, ",") {
+			s := user.Sort{Field: strings.TrimSpace(field)}
+			if strings.HasPrefix(s.Field, "-") {
+				s.Field, s.Desc = s.Field[1:], true
+			}
+			q.Sort = append(q.Sort, s)
+		}
+	}
+
+	// The parameters are read in order so that the filters are the same for the same query string
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == sortParam || key == limitParam || key == cursorParam {
+			continue
+		}
+
+		field, op := key, ""
+		if i := strings.Index(key, "["); i > 0 && strings.HasSuffix(key, "]") {
+			field, op = key[:i], key[i+1:len(key)-1]
+		}
+		for _, value := range params[key] {
+			q.Filters = append(q.Filters, user.Filter{Field: field, Op: op, Value: value})
+		}
+	}
+
+	return q, nil
+}
@@ -0,0 +1,65 @@
+package user
+
+import (
+	"net/url"
+	"os"
+	"testing"
+
+	user "go-boilerplate-api/pkg/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ralstan-vaz/go-errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	t := m.Run()
+	os.Exit(t)
+}
+
+func TestBindListQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  user.ListQuery
+	}{
+		{name: "no parameters", query: "", want: user.ListQuery{}},
+		{name: "filter by value", query: "name=Shepard", want: user.ListQuery{Filters: []user.Filter{{Field: "name", Value: "Shepard"}}}},
+		{name: "filter with an operator", query: "name[prefix]=She", want: user.ListQuery{Filters: []user.Filter{{Field: "name", Op: "prefix", Value: "She"}}}},
+		{name: "filter with an empty operator", query: "name[]=She", want: user.ListQuery{Filters: []user.Filter{{Field: "name", Op: "", Value: "She"}}}},
+		{name: "unclosed bracket is a field", query: "name[prefix=She", want: user.ListQuery{Filters: []user.Filter{{Field: "name[prefix", Value: "She"}}}},
+		{name: "repeated filter", query: "name=Shepard&name=Tali", want: user.ListQuery{Filters: []user.Filter{{Field: "name", Value: "Shepard"}, {Field: "name", Value: "Tali"}}}},
+		{
+			name:  "filters in the order of the fields",
+			query: "name[contains]=ep&id=1",
+			want:  user.ListQuery{Filters: []user.Filter{{Field: "id", Value: "1"}, {Field: "name", Op: "contains", Value: "ep"}}},
+		},
+		{name: "sort", query: "sort=-name,id", want: user.ListQuery{Sort: []user.Sort{{Field: "name", Desc: true}, {Field: "id"}}}},
+		{name: "sort with spaces", query: "sort=name,%20-id", want: user.ListQuery{Sort: []user.Sort{{Field: "name"}, {Field: "id", Desc: true}}}},
+		{name: "limit and cursor", query: "limit=10&cursor=abc", want: user.ListQuery{Limit: 10, Cursor: "abc"}},
+		{
+			name:  "all the parameters",
+			query: "name[prefix]=She&sort=-name&limit=5&cursor=abc",
+			want:  user.ListQuery{Filters: []user.Filter{{Field: "name", Op: "prefix", Value: "She"}}, Sort: []user.Sort{{Field: "name", Desc: true}}, Limit: 5, Cursor: "abc"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params, err := url.ParseQuery(test.query)
+			assert.Nil(t, err)
+
+			q, err := bindListQuery(params)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, q)
+		})
+	}
+}
+
+func TestBindListQueryInvalidLimit(t *testing.T) {
+	_, err := bindListQuery(url.Values{"limit": {"ten"}})
+	assert.NotNil(t, err)
+	assert.Equal(t, "APIS.HTTP.USER.INVALID_LIMIT", errors.Get(err).Code)
+}
@@ -15,9 +15,12 @@ func bindRoutes(router *gin.Engine, deps *shared.Deps) {
 	service := NewUserService(deps.Config, deps.Database, deps.Apm, deps.HTTPRequester, deps.GrpcConn)
 	userAPI := router.Group("/users")
 	{
-		userAPI.GET("/", service.getAll)
+		userAPI.GET("/", service.list)
 		userAPI.GET("/:userId", service.getOne)
 		userAPI.GET("/:userId/rating", service.getWithInfo)
 		userAPI.POST("/", service.insert)
+		userAPI.PUT("/:userId", service.update)
+		userAPI.PATCH("/:userId", service.patch)
+		userAPI.DELETE("/:userId", service.delete)
 	}
 }
@@ -2,13 +2,23 @@ package utils
 
 import (
 	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/redact"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ralstan-vaz/go-errors"
 	"github.com/ralstan-vaz/go-errors/http"
 )
 
-// HandleError formats, logs and sets a http response for the error
+// UnsupportedMediaType is the kind of the errors of the requests whose body is of a media type the route does not accept
+const UnsupportedMediaType errors.Kind = "UnsupportedMediaType"
+
+// statusCodes are the status codes of the kinds go-errors does not map
+var statusCodes = map[errors.Kind]int{
+	UnsupportedMediaType: 415,
+}
+
+// HandleError formats, logs and sets a http response for the error.
+// The description sent to the client is redacted by the redaction policy
 func HandleError(c *gin.Context, errObj *error) {
 
 	if *errObj == nil {
@@ -21,13 +31,22 @@ func HandleError(c *gin.Context, errObj *error) {
 		err.Message = "Something Went Wrong"
 	}
 
-	log.Error(err.Code, err.Description, log.Priority1, err.Source)
+	log.FromContext(c.Request.Context()).Error(err.Code, err.Description, log.Priority1, err.Source)
 
-	statusCode := http.StatusCode(err)
+	statusCode, ok := statusCodes[err.Kind]
+	if !ok {
+		statusCode = http.StatusCode(err)
+	}
 	c.JSON(statusCode, gin.H{
 		"code":        err.Code,
 		"message":     err.Message,
-		"description": err.Description,
+		"description": redact.Current().String(err.Description),
 	})
 
 }
+
+// WithLogFields scopes the logs of the request with the fields, including the error logged by HandleError
+func WithLogFields(c *gin.Context, fields log.Fields) {
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(log.NewContext(ctx, log.FromContext(ctx).WithFields(fields)))
+}
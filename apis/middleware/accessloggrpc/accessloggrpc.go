@@ -0,0 +1,144 @@
+// Package accessloggrpc provides interceptors for the access logs of gRPC.
+package accessloggrpc
+
+import (
+	"context"
+	"go-boilerplate-api/config"
+	log "go-boilerplate-api/pkg/utils/logger"
+	"net"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// writes the structured access log of the requests through the logger of the context.
+//
+// The failed requests are always logged as warnings whatever the log level, the successful ones are
+// sampled and the excluded full methods are never logged.
+func UnaryServerInterceptor(conf config.IConfig) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		write(ctx, conf, info.FullMethod, err, start, log.Fields{
+			"bytesIn":  size(req),
+			"bytesOut": size(resp),
+		})
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// writes the structured access log of the streams once they end, like UnaryServerInterceptor.
+//
+// The log carries the number of messages received and sent along with their total size.
+func StreamServerInterceptor(conf config.IConfig) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		start := time.Now()
+		counted := &countingStream{ServerStream: stream}
+		err := handler(srv, counted)
+
+		write(stream.Context(), conf, info.FullMethod, err, start, log.Fields{
+			"messagesIn":  counted.messagesIn,
+			"messagesOut": counted.messagesOut,
+			"bytesIn":     counted.bytesIn,
+			"bytesOut":    counted.bytesOut,
+		})
+		return err
+	}
+}
+
+// write writes the access log of the request with the fields through the logger of the context,
+// as a warning if the request failed, whatever the log level, and only if the access options log it
+func write(ctx context.Context, conf config.IConfig, fullMethod string, err error, start time.Time, fields log.Fields) {
+	code := status.Code(err)
+	failed := code != codes.OK
+	access := conf.Get().Log.Access
+	opts := log.AccessOptions{Enabled: access.Enabled, SuccessSampleRate: access.SuccessSampleRate, Exclude: access.Exclude}
+	if !opts.Logs(failed, fullMethod) {
+		return
+	}
+
+	fields["protocol"] = "grpc"
+	fields["method"] = fullMethod
+	fields["code"] = code.String()
+	fields["latencyMs"] = float64(time.Since(start)) / float64(time.Millisecond)
+	fields["clientIp"] = clientIP(ctx)
+	fields["userAgent"] = userAgent(ctx)
+
+	log.FromContext(ctx).Access(failed, "GRPC request", fields)
+}
+
+// countingStream counts the messages received and sent on the stream along with their size
+type countingStream struct {
+	grpc.ServerStream
+	messagesIn, messagesOut int
+	bytesIn, bytesOut       int
+}
+
+// SendMsg ...
+func (s *countingStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.messagesOut++
+		s.bytesOut += size(m)
+	}
+	return err
+}
+
+// RecvMsg ...
+func (s *countingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.messagesIn++
+		s.bytesIn += size(m)
+	}
+	return err
+}
+
+// size returns the encoded size of the message, 0 if it is not a proto message
+func size(message interface{}) int {
+	m, ok := message.(proto.Message)
+	if !ok || m == nil {
+		return 0
+	}
+	return proto.Size(m)
+}
+
+// clientIP returns the IP of the peer of the request
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// userAgent returns the user agent of the incoming metadata
+func userAgent(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("user-agent"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package accessloggrpc
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"go-boilerplate-api/config"
+	log "go-boilerplate-api/pkg/utils/logger"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+// staticConfig is an IConfig that always returns the same config
+type staticConfig struct {
+	conf *config.Config
+}
+
+func (s *staticConfig) Get() *config.Config                        { return s.conf }
+func (s *staticConfig) Subscribe(fn func(old, new *config.Config)) {}
+func (s *staticConfig) Close() error                               { return nil }
+
+// captureLogs returns what the logger writes while fn runs with the log level
+func captureLogs(t *testing.T, level string, fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, logger := os.Stdout, os.Stderr, log.Logger
+	os.Stdout, os.Stderr = w, w
+	defer func() {
+		os.Stdout, os.Stderr, log.Logger = stdout, stderr, logger
+		log.SetLevels(log.Levels{Level: "debug"})
+	}()
+
+	log.InitLogger()
+	assert.Nil(t, log.SetLevels(log.Levels{Level: level}))
+	fn()
+	w.Close()
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestUnaryServerInterceptorAtLevelError(t *testing.T) {
+	conf := &config.Config{}
+	conf.Log.Access.Enabled = true
+	conf.Log.Access.SuccessSampleRate = 1
+	interceptor := UnaryServerInterceptor(&staticConfig{conf: conf})
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if req == "missing" {
+			return nil, status.Error(codes.NotFound, "not found")
+		}
+		return nil, nil
+	}
+
+	out := captureLogs(t, "error", func() {
+		interceptor(context.Background(), "missing", &grpc.UnaryServerInfo{FullMethod: "/proto.UserService/GetOne"}, handler)
+		interceptor(context.Background(), "found", &grpc.UnaryServerInfo{FullMethod: "/proto.UserService/GetAll"}, handler)
+	})
+
+	// The failed request is logged whatever the level, the successful one is filtered by it
+	assert.Contains(t, out, "/proto.UserService/GetOne")
+	assert.Contains(t, out, "NotFound")
+	assert.NotContains(t, out, "/proto.UserService/GetAll")
+}
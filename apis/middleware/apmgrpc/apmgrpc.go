@@ -3,12 +3,12 @@ package apmgrpc
 
 import (
 	"context"
-	"fmt"
 	"go-boilerplate-api/apm"
 	log "go-boilerplate-api/pkg/utils/logger"
 
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"google.golang.org/grpc"
-	"stash.bms.bz/bms/monitoringsystem"
+	"google.golang.org/grpc/metadata"
 )
 
 var (
@@ -17,7 +17,7 @@ var (
 
 // options options for creating a request context object
 type options struct {
-	apm *monitoringsystem.Agent
+	apm apm.HandlerInterface
 }
 
 func evaluateOptions(opts []Option) *options {
@@ -33,9 +33,9 @@ func evaluateOptions(opts []Option) *options {
 type Option func(*options)
 
 // WithAPM customizes the function for monitoring the request performance
-func WithAPM(apm *monitoringsystem.Agent) Option {
+func WithAPM(apmHandler apm.HandlerInterface) Option {
 	return func(o *options) {
-		o.apm = apm
+		o.apm = apmHandler
 	}
 }
 
@@ -53,22 +53,13 @@ func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
 		handler grpc.UnaryHandler,
 	) (resp interface{}, err error) {
 		if o.apm != nil {
-			// Starts an APM transaction
-			tx, err := o.apm.StartTransaction(info.FullMethod)
-			if err != nil || tx == nil {
-				log.Error("GO-BOILERPLATE.GRPC.APM_TRANS_INIT_FAIL", "Transaction failed", log.Priority1, nil, map[string]interface{}{"error": err.Error()})
-			}
-
-			// Stores transaction details in context
-			ctx = context.WithValue(ctx, apm.TransactionKey, tx)
-
-			// Ends transaction
-			defer func(ctx context.Context, opts *options) {
-				if err != nil {
-					err = fmt.Errorf("panic: %v", err)
-				}
+			var tx interface{}
+			ctx, tx = startTransaction(ctx, o.apm, info.FullMethod)
+
+			// Ends transaction along with the error returned by the handler
+			defer func(opts *options) {
 				opts.apm.EndTransaction(tx, err)
-			}(ctx, o)
+			}(o)
 		}
 
 		resp, err = handler(ctx, req)
@@ -76,3 +67,86 @@ func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
 
 	}
 }
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// traces gRPC streams with the given options.
+//
+// The interceptor will trace one transaction with the "grpc" type for each
+// stream, from its start until the handler returns.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := evaluateOptions(opts)
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		if o.apm == nil {
+			return handler(srv, stream)
+		}
+
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		var tx interface{}
+		wrapped.WrappedContext, tx = startTransaction(stream.Context(), o.apm, info.FullMethod)
+
+		// Ends transaction along with the error returned by the handler
+		defer func(opts *options) {
+			opts.apm.EndTransaction(tx, err)
+		}(o)
+
+		return handler(srv, wrapped)
+	}
+}
+
+// startTransaction starts an APM transaction continuing the trace of the incoming metadata, if any,
+// and returns the context carrying it along with the transaction
+func startTransaction(ctx context.Context, apmHandler apm.HandlerInterface, fullMethod string) (context.Context, interface{}) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tx, txErr := apmHandler.StartRemoteTransaction(fullMethod, apm.MetadataCarrier(md))
+	if txErr != nil {
+		log.FromContext(ctx).Error("GO-BOILERPLATE.GRPC.APM_TRANS_INIT_FAIL", "Transaction failed", log.Priority1, nil, map[string]interface{}{"error": txErr.Error()})
+	}
+
+	// Stores transaction details in context
+	ctx = context.WithValue(ctx, apm.TransactionKey, tx)
+	// Scopes the logs of the request with the trace ID, if the handler has one
+	if traceID := apm.TraceID(tx); traceID != "" {
+		ctx = log.NewContext(ctx, log.FromContext(ctx).WithField("traceId", traceID))
+	}
+	return ctx, tx
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that
+// traces the outgoing gRPC requests with the given options.
+//
+// The interceptor starts an external segment in the transaction of the
+// context and propagates its trace context through the outgoing metadata.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	o := evaluateOptions(opts)
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		callOpts ...grpc.CallOption,
+	) error {
+		if o.apm == nil {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		}
+
+		segment, err := o.apm.StartExternalSegment(ctx, method)
+		if err != nil {
+			log.FromContext(ctx).Error("GO-BOILERPLATE.GRPC.APM_SEGMENT_INIT_FAIL", "Segment failed", log.Priority1, nil, map[string]interface{}{"error": err.Error()})
+		}
+		defer o.apm.EndExternalSegment(segment)
+
+		// Copies the outgoing metadata so that the metadata of the caller is not modified
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		o.apm.Inject(segment, apm.MetadataCarrier(md))
+		ctx = metadata.NewOutgoingContext(ctx, md)
+
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+}
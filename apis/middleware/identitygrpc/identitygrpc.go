@@ -0,0 +1,64 @@
+// Package identitygrpc provides interceptors for the identity of the clients authenticated with their certificate.
+package identitygrpc
+
+import (
+	"context"
+	"go-boilerplate-api/pkg/utils/identity"
+	log "go-boilerplate-api/pkg/utils/logger"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that stores the identity
+// of the client authenticated with its certificate (mTLS) in the context.
+//
+// The logs of the request are scoped with the common name of the certificate.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return handler(withIdentity(ctx), req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that stores the identity
+// of the client authenticated with its certificate (mTLS) in the context of the stream, like UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = withIdentity(stream.Context())
+		return handler(srv, wrapped)
+	}
+}
+
+// withIdentity returns the context with the identity of the verified client certificate, if any,
+// and the logs scoped with its common name
+func withIdentity(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ctx
+	}
+
+	id := identity.FromConnectionState(&tlsInfo.State)
+	if id == nil {
+		return ctx
+	}
+
+	ctx = identity.NewContext(ctx, id)
+	return log.NewContext(ctx, log.FromContext(ctx).WithField("clientCn", id.CommonName))
+}
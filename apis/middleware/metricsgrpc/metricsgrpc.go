@@ -0,0 +1,61 @@
+// Package metricsgrpc provides interceptors for the RED metrics of gRPC.
+package metricsgrpc
+
+import (
+	"context"
+	"go-boilerplate-api/pkg/metrics"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// records the count, the errors and the latency of the requests per full method.
+func UnaryServerInterceptor(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.ObserveGRPC(info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// records the count, the errors and the duration of the streams per full method.
+func StreamServerInterceptor(m *metrics.Metrics) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		m.ObserveGRPC(info.FullMethod, status.Code(err), time.Since(start))
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that
+// records the count, the errors and the latency of the outgoing calls per full method.
+func UnaryClientInterceptor(m *metrics.Metrics) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		callOpts ...grpc.CallOption,
+	) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		m.ObserveClient(metrics.ClientGRPC, method, status.Code(err).String(), err != nil, time.Since(start))
+		return err
+	}
+}
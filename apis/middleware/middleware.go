@@ -1,44 +1,64 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"go-boilerplate-api/apm"
+	"go-boilerplate-api/config"
+	"go-boilerplate-api/pkg/metrics"
+	"go-boilerplate-api/pkg/utils/identity"
 	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/redact"
+	"go-boilerplate-api/pkg/utils/requestid"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	pkgErrors "github.com/pkg/errors"
-	"stash.bms.bz/bms/monitoringsystem"
 )
 
-// HandlePanic ... rest panic handler
-func HandlePanic(c *gin.Context) {
+// HandlePanic ... rest panic handler, the panic is noticed in the apm through the handler.
+// The error and the stack trace of the panic are sent to the client only if redaction.exposeStackTrace is set
+func HandlePanic(apmHandler apm.HandlerInterface, conf config.IConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handlePanic(c, apmHandler, conf)
+	}
+}
+
+// handlePanic recovers from a panic in the handlers that follow it
+func handlePanic(c *gin.Context, apmHandler apm.HandlerInterface, conf config.IConfig) {
 	defer func(c *gin.Context) {
 		r := recover()
 		var stackTrace string
 		if r != nil {
 			err, ok := r.(error)
 			if ok {
-				// Logs the error
+				// Logs the error, the logger redacts it
 				stackTrace = fmt.Sprintf("%+v", pkgErrors.New(err.Error()))
-				log.Error("GO-BOILERPLATE.PANIC", "Unexpected panic occured", log.Priority1, nil, map[string]interface{}{"error": err.Error(), "stackTrace": stackTrace})
+				log.FromContext(c.Request.Context()).Error("GO-BOILERPLATE.PANIC", "Unexpected panic occured", log.Priority1, nil, map[string]interface{}{"error": err.Error(), "stackTrace": stackTrace})
 
 				// Notice error in apm
-				apm.APM.NoticeError(apm.FromContext(c), err)
+				apmHandler.NoticeError(apm.FromContext(c), err)
 
-				// Forms error message
-				c.JSON(500, gin.H{
-					"message":    "Panic: Unexpected error occured.",
-					"error":      err.Error(),
-					"stackTrace": stackTrace,
-				})
+				// Forms error message, the error and the stack trace are only exposed in the development tiers
+				response := gin.H{
+					"message": "Panic: Unexpected error occured.",
+				}
+				if conf.Get().Redaction.ExposeStackTrace {
+					policy := redact.Current()
+					response["error"] = policy.String(err.Error())
+					response["stackTrace"] = policy.String(stackTrace)
+				}
+				c.JSON(500, response)
 			} else {
 				// Logs the error
-				log.Error("GO-BOILERPLATE.PANIC", "Panic recovery failed to parse error", log.Priority1, nil, map[string]interface{}{"error": r})
+				log.FromContext(c.Request.Context()).Error("GO-BOILERPLATE.PANIC", "Panic recovery failed to parse error", log.Priority1, nil, map[string]interface{}{"error": r})
 
 				// Notice error in apm
-				apm.APM.NoticeError(apm.FromContext(c), errors.New("GO-BOILERPLATE.UNRECOVERED.PANIC"))
+				apmHandler.NoticeError(apm.FromContext(c), errors.New("GO-BOILERPLATE.UNRECOVERED.PANIC"))
 
 				// Forms error message
 				c.JSON(500, gin.H{
@@ -54,50 +74,200 @@ func HandlePanic(c *gin.Context) {
 //
 //	router := gin.Default()
 //	// Add the the middleware before other middlewares or routes:
-//	router.Use(Middleware(apm))
+//	router.Use(ApmMiddleware(apmHandler))
 //
 // Input
-//		apmAgent: instance of the apm
+//		apmHandler: the apm handler, see apm.NewApmHandler
 // Output
 //		ginFun: gin http handler function
-func ApmMiddleware(apmAgent *monitoringsystem.Agent) gin.HandlerFunc {
+func ApmMiddleware(apmHandler apm.HandlerInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if apmAgent != nil {
-			// Starts an APM transaction
-			name := c.HandlerName()
-
-			// Removes any sensitive query params here ... (skip / remove block if no such params exist)
-			req := c.Request
-			rawQuery := req.URL.RawQuery
-			queryParams := req.URL.Query()
-			// queryParams.Del("sensitive_query_params")
-			req.URL.RawQuery = queryParams.Encode()
-
-			txn, err := apmAgent.StartWebTransaction(name, c.Writer, req)
-			c.Request.URL.RawQuery = rawQuery
-
-			// defer End transaction
-			defer func(c *gin.Context) {
-				var err error
-				if c.Writer.Status() != http.StatusOK {
-					err = errors.New("GO-BOILERPLATE.ERROR")
-				}
-				apmAgent.EndTransaction(apm.FromContext(c), err)
-			}(c)
-
-			// Handles error incase transaction fails
-			if err != nil {
-				// Place holder code until new errors library is implemented properly
-				log.Error("GO-BOILERPLATE.REST.APM_TRANS_INIT_FAIL", "Transaction failed", log.Priority1, nil, map[string]interface{}{"error": err.Error()})
-				c.Next()
-			} else if txn == nil {
-				log.Error("GO-BOILERPLATE.REST.APM_TRANS_INIT_FAIL", "Transaction is nil", log.Priority1, nil, map[string]interface{}{"transaction": txn})
-				c.Next()
+		// Starts an APM transaction
+		name := c.HandlerName()
+
+		// The apm handlers report the request with its query string redacted by the redaction policy
+		txn, err := apmHandler.StartWebTransaction(name, c.Writer, c.Request)
+
+		// Handles error incase transaction fails
+		if err != nil {
+			// Place holder code until new errors library is implemented properly
+			log.FromContext(c.Request.Context()).Error("GO-BOILERPLATE.REST.APM_TRANS_INIT_FAIL", "Transaction failed", log.Priority1, nil, map[string]interface{}{"error": err.Error()})
+			c.Next()
+			return
+		}
+
+		// Stores transaction details in context, the request context carries it to the outgoing calls
+		c.Set(apm.TransactionKey, txn)
+		ctx := context.WithValue(c.Request.Context(), apm.TransactionKey, txn)
+		// Scopes the logs of the request with the trace ID, if the handler has one
+		if traceID := apm.TraceID(txn); traceID != "" {
+			ctx = log.NewContext(ctx, log.FromContext(ctx).WithField("traceId", traceID))
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		// defer End transaction
+		defer func(c *gin.Context) {
+			var err error
+			if c.Writer.Status() != http.StatusOK {
+				err = errors.New("GO-BOILERPLATE.ERROR")
 			}
+			apmHandler.AddAttribute(apm.FromContext(c), "http.status_code", c.Writer.Status())
+			apmHandler.EndTransaction(apm.FromContext(c), err)
+		}(c)
+
+		c.Next()
+	}
+}
+
+// MetricsMiddleware creates a middleware recording the count, the errors and the latency of the requests.
+// The requests are labelled with their route template eg. /users/:userId rather than their path
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		m.ObserveHTTP(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// RequestIDMiddleware creates a middleware that identifies every request with the X-Request-ID header.
+// The ID sent by the client is kept if it is valid, otherwise a new one is generated.
+// It is sent back in the response header and stored in the request context for the logs and the outgoing calls
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := requestid.Resolve(c.GetHeader(requestid.Header))
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Next()
+	}
+}
 
-			// Stores transaction details in context
-			c.Set(apm.TransactionKey, txn)
+// ClientIdentityMiddleware creates a middleware that stores the identity of the client authenticated with its certificate (mTLS)
+// in the request context, it also scopes the logs of the request with the common name of the certificate
+func ClientIdentityMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := identity.FromConnectionState(c.Request.TLS)
+		if id == nil {
+			c.Next()
+			return
 		}
+
+		ctx := identity.NewContext(c.Request.Context(), id)
+		ctx = log.NewContext(ctx, log.FromContext(ctx).WithField("clientCn", id.CommonName))
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
+
+// AccessLogMiddleware creates a middleware writing the structured access log of the requests through the logger of the request context.
+// The failed requests (4xx and 5xx) are always logged as warnings whatever the log level, the successful ones are sampled and the excluded paths are never logged
+func AccessLogMiddleware(conf config.IConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		failed := status >= http.StatusBadRequest
+		access := conf.Get().Log.Access
+		opts := log.AccessOptions{Enabled: access.Enabled, SuccessSampleRate: access.SuccessSampleRate, Exclude: access.Exclude}
+		if !opts.Logs(failed, c.Request.URL.Path, c.FullPath()) {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = metrics.UnmatchedRoute
+		}
+
+		fields := log.Fields{
+			"protocol":  "http",
+			"method":    c.Request.Method,
+			"route":     route,
+			"path":      c.Request.URL.Path,
+			"status":    status,
+			"latencyMs": float64(time.Since(start)) / float64(time.Millisecond),
+			"bytesIn":   nonNegative(c.Request.ContentLength),
+			"bytesOut":  nonNegative(int64(c.Writer.Size())),
+			"clientIp":  c.ClientIP(),
+			"userAgent": c.Request.UserAgent(),
+		}
+
+		log.FromContext(c.Request.Context()).Access(failed, "HTTP request", fields)
+	}
+}
+
+// TrustedProxiesMiddleware creates a middleware that only lets the trusted proxies set the client IP returned by gin.
+// The X-Forwarded-For and X-Real-Ip headers of the requests from any other address are removed, and X-Forwarded-For
+// is replaced by its last address that is not a trusted proxy, the address of the client as seen by the outermost trusted proxy.
+// The proxies are IPs or CIDRs, the invalid ones are skipped as the config validates them
+func TrustedProxiesMiddleware(proxies []string) gin.HandlerFunc {
+	trusted := parseProxies(proxies)
+	isTrusted := func(address string) bool {
+		ip := net.ParseIP(strings.TrimSpace(address))
+		if ip == nil {
+			return false
+		}
+		for _, network := range trusted {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(c *gin.Context) {
+		remote, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+		if err != nil || !isTrusted(remote) {
+			c.Request.Header.Del("X-Forwarded-For")
+			c.Request.Header.Del("X-Real-Ip")
+			c.Next()
+			return
+		}
+
+		if forwarded := c.GetHeader("X-Forwarded-For"); forwarded != "" {
+			addresses := strings.Split(forwarded, ",")
+			client := strings.TrimSpace(addresses[0])
+			for i := len(addresses) - 1; i >= 0; i-- {
+				if !isTrusted(addresses[i]) {
+					client = strings.TrimSpace(addresses[i])
+					break
+				}
+			}
+			c.Request.Header.Set("X-Forwarded-For", client)
+		}
+		c.Next()
+	}
+}
+
+// parseProxies parses the IPs and the CIDRs of the proxies, an IP is a network of its own
+func parseProxies(proxies []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				continue
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// nonNegative returns 0 for the unknown sizes, which are negative
+func nonNegative(size int64) int64 {
+	if size < 0 {
+		return 0
+	}
+	return size
+}
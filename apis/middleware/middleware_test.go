@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"go-boilerplate-api/config"
+	log "go-boilerplate-api/pkg/utils/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	t := m.Run()
+	os.Exit(t)
+}
+
+// staticConfig is an IConfig that always returns the same config
+type staticConfig struct {
+	conf *config.Config
+}
+
+func (s *staticConfig) Get() *config.Config                        { return s.conf }
+func (s *staticConfig) Subscribe(fn func(old, new *config.Config)) {}
+func (s *staticConfig) Close() error                               { return nil }
+
+// captureLogs returns what the logger writes while fn runs with the log level
+func captureLogs(t *testing.T, level string, fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, logger := os.Stdout, os.Stderr, log.Logger
+	os.Stdout, os.Stderr = w, w
+	defer func() {
+		os.Stdout, os.Stderr, log.Logger = stdout, stderr, logger
+		log.SetLevels(log.Levels{Level: "debug"})
+	}()
+
+	log.InitLogger()
+	assert.Nil(t, log.SetLevels(log.Levels{Level: level}))
+	fn()
+	w.Close()
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestAccessLogMiddlewareAtLevelError(t *testing.T) {
+	conf := &config.Config{}
+	conf.Log.Access.Enabled = true
+	conf.Log.Access.SuccessSampleRate = 1
+
+	router := gin.New()
+	router.Use(AccessLogMiddleware(&staticConfig{conf: conf}))
+	router.GET("/users/:userId", func(c *gin.Context) {
+		if c.Param("userId") == "missing" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	out := captureLogs(t, "error", func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/missing", nil))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	})
+
+	// The failed request is logged whatever the level, the successful one is filtered by it
+	assert.Contains(t, out, "/users/missing")
+	assert.NotContains(t, out, "/users/1")
+}
+
+func TestTrustedProxiesMiddleware(t *testing.T) {
+	proxies := []string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32", "not-an-ip"}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded string
+		realIP    string
+		// wantForwarded and wantRealIP are the headers seen by the handlers
+		wantForwarded string
+		wantRealIP    string
+		clientIP      string
+	}{
+		{name: "untrusted remote ignores the headers", remote: "203.0.113.7:1234", forwarded: "198.51.100.1", realIP: "198.51.100.2", clientIP: "203.0.113.7"},
+		{name: "invalid remote ignores the headers", remote: "unix-socket", forwarded: "198.51.100.1", clientIP: ""},
+		{name: "trusted remote without the headers", remote: "10.1.2.3:1234", clientIP: "10.1.2.3"},
+		{name: "trusted remote keeps the real ip", remote: "10.1.2.3:1234", realIP: "198.51.100.2", wantRealIP: "198.51.100.2", clientIP: "198.51.100.2"},
+		{name: "trusted bare ip", remote: "192.168.1.10:1234", forwarded: "198.51.100.1", wantForwarded: "198.51.100.1", clientIP: "198.51.100.1"},
+		{name: "ip next to a trusted bare ip is untrusted", remote: "192.168.1.11:1234", forwarded: "198.51.100.1", clientIP: "192.168.1.11"},
+		{name: "chain of trusted proxies", remote: "10.0.0.1:1234", forwarded: "198.51.100.1, 10.0.0.3, 192.168.1.10", wantForwarded: "198.51.100.1", clientIP: "198.51.100.1"},
+		{name: "right-most untrusted address wins", remote: "10.0.0.1:1234", forwarded: "1.1.1.1, 198.51.100.1, 10.0.0.3", wantForwarded: "198.51.100.1", clientIP: "198.51.100.1"},
+		{name: "all trusted keeps the left-most address", remote: "10.0.0.1:1234", forwarded: "10.0.0.5, 10.0.0.3", wantForwarded: "10.0.0.5", clientIP: "10.0.0.5"},
+		{name: "invalid forwarded entry is untrusted", remote: "10.0.0.1:1234", forwarded: "198.51.100.1, garbage, 10.0.0.3", wantForwarded: "garbage", clientIP: "garbage"},
+		{name: "trusted ipv6 cidr", remote: "[2001:db8::1]:1234", forwarded: "2001:db8:ffff::1, 198.51.100.1, 2001:db8::2", wantForwarded: "198.51.100.1", clientIP: "198.51.100.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var clientIP, forwarded, realIP string
+			router := gin.New()
+			router.ForwardedByClientIP = true
+			router.Use(TrustedProxiesMiddleware(proxies))
+			router.GET("/", func(c *gin.Context) {
+				clientIP = c.ClientIP()
+				forwarded = c.GetHeader("X-Forwarded-For")
+				realIP = c.GetHeader("X-Real-Ip")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = test.remote
+			if test.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", test.forwarded)
+			}
+			if test.realIP != "" {
+				req.Header.Set("X-Real-Ip", test.realIP)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, test.wantForwarded, forwarded)
+			assert.Equal(t, test.wantRealIP, realIP)
+			assert.Equal(t, test.clientIP, clientIP)
+		})
+	}
+}
+
+func TestParseProxies(t *testing.T) {
+	tests := []struct {
+		name     string
+		proxies  []string
+		networks []string
+	}{
+		{name: "no proxies", proxies: nil, networks: []string{}},
+		{name: "bare ipv4", proxies: []string{"192.168.1.10"}, networks: []string{"192.168.1.10/32"}},
+		{name: "bare ipv6", proxies: []string{"2001:db8::1"}, networks: []string{"2001:db8::1/128"}},
+		{name: "cidr", proxies: []string{"10.0.0.0/8", "2001:db8::/32"}, networks: []string{"10.0.0.0/8", "2001:db8::/32"}},
+		{name: "cidr with host bits", proxies: []string{"10.1.2.3/16"}, networks: []string{"10.1.0.0/16"}},
+		{name: "invalid entries are skipped", proxies: []string{"not-an-ip", "10.0.0.0/33", "300.1.1.1", "", "172.16.0.0/12"}, networks: []string{"172.16.0.0/12"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			networks := []string{}
+			for _, network := range parseProxies(test.proxies) {
+				networks = append(networks, network.String())
+			}
+			assert.Equal(t, test.networks, networks)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Package requestidgrpc provides interceptors for the request ID of gRPC.
+package requestidgrpc
+
+import (
+	"context"
+	"go-boilerplate-api/pkg/utils/requestid"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// identifies every request with the x-request-id metadata.
+//
+// The ID sent by the client is kept if it is valid, otherwise a new one is
+// generated. It is sent back in the trailer and stored in the context.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		id := incomingID(ctx)
+
+		// Fails only when there is no grpc stream eg. when the handler is invoked directly
+		grpc.SetTrailer(ctx, metadata.Pairs(requestid.MetadataKey, id))
+
+		return handler(requestid.NewContext(ctx, id), req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// identifies every stream with the x-request-id metadata, like UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		id := incomingID(stream.Context())
+		stream.SetTrailer(metadata.Pairs(requestid.MetadataKey, id))
+
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = requestid.NewContext(stream.Context(), id)
+		return handler(srv, wrapped)
+	}
+}
+
+// incomingID returns the request ID of the incoming metadata if it is valid, a new one otherwise
+func incomingID(ctx context.Context) string {
+	var id string
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(requestid.MetadataKey); len(values) > 0 {
+		id = values[0]
+	}
+	return requestid.Resolve(id)
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that
+// forwards the request ID of the context in the outgoing metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		callOpts ...grpc.CallOption,
+	) error {
+		if id := requestid.FromContext(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, requestid.MetadataKey, id)
+		}
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+}
@@ -0,0 +1,71 @@
+// Package routes lists the routes served by the app.
+package routes
+
+import (
+	"context"
+	"go-boilerplate-api/apis/grpc"
+	"go-boilerplate-api/apis/http"
+	"go-boilerplate-api/shared"
+	"sort"
+)
+
+// Route is an http route or a grpc method served by the app
+type Route struct {
+	// Protocol is either http or grpc
+	Protocol string `json:"protocol"`
+	// Method is the http method or the kind of rpc eg. unary, server-stream
+	Method string `json:"method"`
+	// Path is the http path or the full grpc method name eg. /proto.UserService/GetAll
+	Path string `json:"path"`
+	// Handler is the name of the function handling the http route
+	Handler string `json:"handler,omitempty"`
+}
+
+// List lists the http routes and the grpc methods of the app.
+// The servers are built but never started, so the dependencies do not need to be connected
+func List(deps *shared.Deps) []Route {
+	routes := []Route{}
+	for _, route := range http.NewRouter(deps).Routes() {
+		routes = append(routes, Route{Protocol: "http", Method: route.Method, Path: route.Path, Handler: route.Handler})
+	}
+	sortRoutes(routes)
+
+	// The context is cancelled so that the health service of the server does not keep checking
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	server, _ := grpc.NewServer(ctx, deps)
+
+	grpcRoutes := []Route{}
+	for service, info := range server.GetServiceInfo() {
+		for _, method := range info.Methods {
+			grpcRoutes = append(grpcRoutes, Route{Protocol: "grpc", Method: rpcKind(method.IsClientStream, method.IsServerStream), Path: "/" + service + "/" + method.Name})
+		}
+	}
+	sortRoutes(grpcRoutes)
+
+	return append(routes, grpcRoutes...)
+}
+
+// sortRoutes sorts the routes by their path and then by their method
+func sortRoutes(routes []Route) {
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+}
+
+// rpcKind names the kind of rpc from its streaming sides
+func rpcKind(clientStream bool, serverStream bool) string {
+	switch {
+	case clientStream && serverStream:
+		return "bidi-stream"
+	case clientStream:
+		return "client-stream"
+	case serverStream:
+		return "server-stream"
+	default:
+		return "unary"
+	}
+}
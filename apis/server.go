@@ -1,28 +1,52 @@
 package apis
 
 import (
+	"context"
 	"go-boilerplate-api/apis/grpc"
 	"go-boilerplate-api/apis/http"
+	"go-boilerplate-api/apis/http/admin"
+	log "go-boilerplate-api/pkg/utils/logger"
 	"go-boilerplate-api/shared"
+	"os"
+	"os/signal"
 	"sync"
+	"syscall"
+	"time"
 )
 
+// defaultShutdownTimeout is used when the config does not specify a shutdown timeout
+const defaultShutdownTimeout = 15 * time.Second
+
 // InitServers will pass the dependencies to the servers.
 // The servers will start in an individual goroutine
 // Wait group is used to wait for all the goroutines launched here to finish.
-// In in ideal scenerio the routines would run indefinitely
-// InitServers will pass the dependencies to the servers.
-// The servers will start in an individual goroutine
-// Wait group is used to wait for all the goroutines launched here to finish.
-// In in ideal scenerio the routines would run indefinitely
+// In in ideal scenerio the routines would run indefinitely, until a SIGINT or SIGTERM is received.
+// On receiving a signal the servers are drained and the dependencies are closed.
 func InitServers(deps *shared.Deps) error {
 	var wg sync.WaitGroup
-	var fatalErrChan = make(chan error)
+	// Buffered so that a server never blocks on reporting its error
+	var fatalErrChan = make(chan error, 3)
 	var wgDone = make(chan bool)
 
+	// Listens for termination signals
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	// Switches the logs to the debug level on SIGUSR1
+	stopLevelSignal := watchLevelSignal(deps.Config)
+	defer stopLevelSignal()
+
 	wg.Add(2)
-	go http.StartServer(deps, &wg, fatalErrChan)
-	go grpc.StartServer(deps, &wg, fatalErrChan)
+	httpServer := http.StartServer(deps, &wg, fatalErrChan)
+	grpcServer := grpc.StartServer(deps, &wg, fatalErrChan)
+
+	// The admin server is only started when it has an address
+	var adminServer *admin.Server
+	if deps.Config.Get().Server.Admin.Address != "" {
+		wg.Add(1)
+		adminServer = admin.StartServer(deps, &wg, fatalErrChan)
+	}
 
 	// Final goroutine to wait until WaitGroup is done
 	go func() {
@@ -30,15 +54,79 @@ func InitServers(deps *shared.Deps) error {
 		close(wgDone)
 	}()
 
-	// Wait until either WaitGroup is done or an error is received through the channel
+	// Wait until either WaitGroup is done, an error is received through the channel or the app is signalled to stop
+	var fatalErr error
+	// The requests are only drained when the app is signalled to stop, a failed listener has none to drain
+	var drain bool
 	select {
 	case <-wgDone:
 		// carry on
 		break
 		// Catch error from  channel and return
-	case err := <-fatalErrChan:
-		close(fatalErrChan)
-		return err
+	case fatalErr = <-fatalErrChan:
+		break
+	case sig := <-quit:
+		log.Info("Received signal " + sig.String() + ", shutting down")
+		drain = true
+	}
+
+	err := shutdown(deps, drain, httpServer, grpcServer, adminServer)
+	if fatalErr != nil {
+		return fatalErr
+	}
+
+	return err
+}
+
+// shutdown drains the servers within the configured timeout and then closes the dependencies.
+// When drain is set the app is reported as not ready for the drain delay before the servers stop accepting requests,
+// the admin server, if any, is shut down along with them
+func shutdown(deps *shared.Deps, drain bool, httpServer *http.Server, grpcServer *grpc.Server, adminServer *admin.Server) error {
+	if drain && deps.Health != nil {
+		deps.Health.Drain()
+		grpcServer.Drain()
+		time.Sleep(deps.Config.Get().Server.DrainDelay)
+	}
+
+	timeout := deps.Config.Get().Server.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var httpErr, grpcErr, adminErr error
+
+	// The servers are drained at the same time so that they share the timeout
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		httpErr = httpServer.Shutdown(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		grpcErr = grpcServer.Shutdown(ctx)
+	}()
+	if adminServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			adminErr = adminServer.Shutdown(ctx)
+		}()
+	}
+	wg.Wait()
+
+	// Dependencies are closed only once the servers stop using them
+	depsErr := deps.Close()
+
+	log.Info("Servers stopped")
+
+	for _, err := range []error{httpErr, grpcErr, adminErr, depsErr} {
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -0,0 +1,45 @@
+//go:build !windows
+// +build !windows
+
+package apis
+
+import (
+	"go-boilerplate-api/config"
+	log "go-boilerplate-api/pkg/utils/logger"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// watchLevelSignal switches the logs to the debug level on SIGUSR1 until log.overrideTTL expires,
+// a new signal restarts the ttl. Returns a function that stops watching
+func watchLevelSignal(conf config.IConfig) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				ttl := conf.Get().Log.OverrideTTL
+				err := log.OverrideLevels(log.Levels{Level: log.LevelDebug.String()}, ttl)
+				if err != nil {
+					newErr := errors.Get(err)
+					log.Error(newErr.Code, "Log levels could not be overridden : "+newErr.Description, log.Priority2, newErr.Source)
+					continue
+				}
+				log.Warn("Received signal SIGUSR1, logging at the debug level for " + ttl.String())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
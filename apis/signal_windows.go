@@ -0,0 +1,13 @@
+//go:build windows
+// +build windows
+
+package apis
+
+import (
+	"go-boilerplate-api/config"
+)
+
+// watchLevelSignal does nothing, there is no SIGUSR1 on windows
+func watchLevelSignal(conf config.IConfig) (stop func()) {
+	return func() {}
+}
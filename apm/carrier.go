@@ -0,0 +1,39 @@
+package apm
+
+import (
+	"google.golang.org/grpc/metadata"
+)
+
+// Carrier carries the trace context between the services eg. the W3C traceparent header.
+// It has the same methods as the OpenTelemetry TextMapCarrier
+type Carrier interface {
+	Get(key string) string
+	Set(key string, value string)
+	Keys() []string
+}
+
+// MetadataCarrier adapts the grpc metadata to a Carrier
+type MetadataCarrier metadata.MD
+
+// Get returns the first value of the key
+func (c MetadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Set sets the value of the key
+func (c MetadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys returns the keys of the metadata
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -2,14 +2,21 @@ package apm
 
 import (
 	"context"
+	"go-boilerplate-api/config"
+	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/redact"
 	"net/http"
+	"net/url"
 
+	"github.com/ralstan-vaz/go-errors"
 	"stash.bms.bz/bms/monitoringsystem"
 )
 
 // HandlerInterface ... A wrapper interface on top of the apm (monitoringsystem) to help out during testing
 type HandlerInterface interface {
 	StartTransaction(name string) (transaction interface{}, err error)
+	StartWebTransaction(name string, w http.ResponseWriter, req *http.Request) (transaction interface{}, err error)
+	StartRemoteTransaction(name string, carrier Carrier) (transaction interface{}, err error)
 	EndTransaction(transaction interface{}, er error) (err error)
 	StartSegment(ctx context.Context, segmentName string) (segment interface{}, err error)
 	EndSegment(segment interface{}) (err error)
@@ -20,24 +27,65 @@ type HandlerInterface interface {
 	StartExternalWebSegment(ctx context.Context, req *http.Request) (externalSegment interface{}, err error)
 	NoticeError(transaction interface{}, err error) error
 	AddAttribute(transaction interface{}, key string, val interface{}) error
+	Inject(segment interface{}, carrier Carrier) error
 }
 
-// Handler ...
+const (
+	// HandlerAgent reports to the apm server through the agent created by Initialize
+	HandlerAgent string = "agent"
+	// HandlerOtel exports OpenTelemetry spans, see OtelHandler
+	HandlerOtel string = "otel"
+	// HandlerRecording keeps the transactions in memory, see RecordingHandler
+	HandlerRecording string = "recording"
+	// HandlerNoop discards the transactions
+	HandlerNoop string = "noop"
+)
+
+// Handler calls the apm agent, it is used only once the agent has been initialized
 type Handler struct {
 }
 
-// NewApmHandler ...
-func NewApmHandler() HandlerInterface {
-	return &Handler{}
+// NewApmHandler creates the apm handler selected in the config.
+// The agent and the otel handlers fall back to the no-op handler if they cannot be initialized
+func NewApmHandler(conf config.IConfig) HandlerInterface {
+	switch conf.Get().APM.Handler {
+	case HandlerAgent:
+		if APM == nil {
+			log.Warn("APM agent is not initialized, falling back to the no-op apm handler")
+			return NewNoopHandler()
+		}
+		return &Handler{}
+	case HandlerOtel:
+		handler, err := NewOtelHandler(conf.Get().APM.Otel, conf.Get().AppVersion)
+		if err != nil {
+			newErr := errors.Get(err)
+			log.Error(newErr.Code, "OpenTelemetry could not be initialized, falling back to the no-op apm handler : "+newErr.Description, log.Priority1, newErr.Source)
+			return NewNoopHandler()
+		}
+		return handler
+	case HandlerRecording:
+		return NewRecordingHandler(defaultRecordingCapacity)
+	default:
+		return NewNoopHandler()
+	}
 }
 
-// NOTE : We could return nil,nil from every function if APM == nil , then we don't need to mock , but I'm a bit split on this atm
-
 // StartTransaction ... Interface function that calls the internal apm functions
 func (a *Handler) StartTransaction(name string) (transaction interface{}, err error) {
 	return APM.StartTransaction(name)
 }
 
+// StartWebTransaction .. Interface function that calls the internal apm functions
+func (a *Handler) StartWebTransaction(name string, w http.ResponseWriter, req *http.Request) (transaction interface{}, err error) {
+	return APM.StartWebTransaction(name, w, redactedRequest(req))
+}
+
+// StartRemoteTransaction .. Interface function that calls the internal apm functions.
+// The agent does not read the trace context of the carrier
+func (a *Handler) StartRemoteTransaction(name string, carrier Carrier) (transaction interface{}, err error) {
+	return APM.StartTransaction(name)
+}
+
 // EndTransaction .. Interface function that calls the internal apm functions
 func (a *Handler) EndTransaction(transaction interface{}, er error) (err error) {
 	return APM.EndTransaction(transaction, er)
@@ -79,7 +127,7 @@ func (a *Handler) EndExternalSegment(segment interface{}) error {
 // StartExternalWebSegment .. Interface function that calls the internal apm functions
 func (a *Handler) StartExternalWebSegment(ctx context.Context, req *http.Request) (externalSegment interface{}, err error) {
 	transaction := FromContext(ctx)
-	return APM.StartExternalWebSegment(transaction, req)
+	return APM.StartExternalWebSegment(transaction, redactedRequest(req))
 }
 
 // NoticeError .. Interface function that calls the internal apm functions
@@ -89,5 +137,29 @@ func (a *Handler) NoticeError(transaction interface{}, err error) error {
 
 // AddAttribute .. Interface function that calls the internal apm functions
 func (a *Handler) AddAttribute(transaction interface{}, key string, val interface{}) error {
-	return APM.AddAttribute(transaction, key, val)
+	return APM.AddAttribute(transaction, key, redact.Current().Value(key, val))
+}
+
+// Inject does nothing, the agent does not propagate the trace context
+func (a *Handler) Inject(segment interface{}, carrier Carrier) error {
+	return nil
+}
+
+// redactedRequest returns a shallow copy of the request whose query string and URL password are redacted,
+// the apm handlers report the copy so that the sensitive params never reach the apm.
+// The headers are shared so that the trace context set on the copy is sent with the request
+func redactedRequest(req *http.Request) *http.Request {
+	policy := redact.Current()
+	redacted := req.WithContext(req.Context())
+
+	u := *req.URL
+	u.RawQuery = policy.Query(req.URL.RawQuery)
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redact.Mask)
+	}
+	redacted.URL = &u
+	if req.RequestURI != "" {
+		redacted.RequestURI = u.RequestURI()
+	}
+	return redacted
 }
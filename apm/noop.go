@@ -0,0 +1,88 @@
+package apm
+
+import (
+	"context"
+	"net/http"
+
+	"stash.bms.bz/bms/monitoringsystem"
+)
+
+// NoopHandler discards all the transactions and segments.
+// It is used when the apm agent is not initialized so that the handler is always safe to call
+type NoopHandler struct {
+}
+
+// NewNoopHandler creates an instance of NoopHandler
+func NewNoopHandler() *NoopHandler {
+	return &NoopHandler{}
+}
+
+// StartTransaction does nothing
+func (a *NoopHandler) StartTransaction(name string) (transaction interface{}, err error) {
+	return nil, nil
+}
+
+// StartWebTransaction does nothing
+func (a *NoopHandler) StartWebTransaction(name string, w http.ResponseWriter, req *http.Request) (transaction interface{}, err error) {
+	return nil, nil
+}
+
+// StartRemoteTransaction does nothing
+func (a *NoopHandler) StartRemoteTransaction(name string, carrier Carrier) (transaction interface{}, err error) {
+	return nil, nil
+}
+
+// EndTransaction does nothing
+func (a *NoopHandler) EndTransaction(transaction interface{}, er error) (err error) {
+	return nil
+}
+
+// StartSegment does nothing
+func (a *NoopHandler) StartSegment(ctx context.Context, segmentName string) (segment interface{}, err error) {
+	return nil, nil
+}
+
+// EndSegment does nothing
+func (a *NoopHandler) EndSegment(segment interface{}) (err error) {
+	return nil
+}
+
+// StartDataStoreSegment does nothing
+func (a *NoopHandler) StartDataStoreSegment(ctx context.Context, segmentName string, operation string, collectionName string, operations ...monitoringsystem.Operation) (datastoreSegment interface{}, err error) {
+	return nil, nil
+}
+
+// EndDataStoreSegment does nothing
+func (a *NoopHandler) EndDataStoreSegment(segment interface{}) (err error) {
+	return nil
+}
+
+// StartExternalSegment does nothing
+func (a *NoopHandler) StartExternalSegment(ctx context.Context, URL string) (externalSegment interface{}, err error) {
+	return nil, nil
+}
+
+// EndExternalSegment does nothing
+func (a *NoopHandler) EndExternalSegment(segment interface{}) error {
+	return nil
+}
+
+// StartExternalWebSegment does nothing
+func (a *NoopHandler) StartExternalWebSegment(ctx context.Context, req *http.Request) (externalSegment interface{}, err error) {
+	return nil, nil
+}
+
+// NoticeError does nothing
+func (a *NoopHandler) NoticeError(transaction interface{}, err error) error {
+	return nil
+}
+
+// AddAttribute does nothing
+func (a *NoopHandler) AddAttribute(transaction interface{}, key string, val interface{}) error {
+	return nil
+}
+
+// Inject does nothing
+func (a *NoopHandler) Inject(segment interface{}, carrier Carrier) error {
+	return nil
+}
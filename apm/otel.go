@@ -0,0 +1,326 @@
+package apm
+
+import (
+	"context"
+	"go-boilerplate-api/config"
+	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/redact"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ralstan-vaz/go-errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"stash.bms.bz/bms/monitoringsystem"
+)
+
+const (
+	// ExporterOTLP sends the spans to an OpenTelemetry collector over grpc
+	ExporterOTLP string = "otlp"
+	// ExporterStdout writes the spans to the standard output
+	ExporterStdout string = "stdout"
+	// ExporterFile appends the spans to a file
+	ExporterFile string = "file"
+
+	// tracerName is the name of the tracer the spans are created with
+	tracerName = "go-boilerplate-api/apm"
+	// otelShutdownTimeout is how long Close waits for the pending spans to be exported
+	otelShutdownTimeout = 5 * time.Second
+)
+
+// OtelHandler reports the transactions and their segments as OpenTelemetry spans.
+// The W3C trace context (traceparent) is extracted from the incoming requests and injected into the outgoing ones
+type OtelHandler struct {
+	provider   *sdktrace.TracerProvider
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	file       *os.File
+}
+
+// otelSpan is the transaction or the segment returned by the OtelHandler.
+// The context carries the span so that its children and the injected trace context refer to it
+type otelSpan struct {
+	ctx  context.Context
+	span trace.Span
+}
+
+// NewOtelHandler creates an instance of OtelHandler exporting the spans through the exporter of the config.
+// The tracer provider and the propagator are also registered globally for the instrumented libraries
+func NewOtelHandler(conf config.Otel, version string) (*OtelHandler, error) {
+	// Set first, the provider reports its errors through the global error handler as soon as it is created
+	otel.SetErrorHandler(otelErrorHandler{})
+
+	exporter, file, err := newSpanExporter(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewWithAttributes(
+		semconv.ServiceNameKey.String(conf.ServiceName),
+		semconv.ServiceVersionKey.String(version),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// The traces started upstream keep the sampling decision of their parent
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.SampleRatio))),
+	)
+
+	handler := newOtelHandler(provider)
+	handler.file = file
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(handler.propagator)
+
+	return handler, nil
+}
+
+// newOtelHandler creates an instance of OtelHandler using the tracer provider
+func newOtelHandler(provider *sdktrace.TracerProvider) *OtelHandler {
+	return &OtelHandler{
+		provider:   provider,
+		tracer:     provider.Tracer(tracerName),
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+}
+
+// newSpanExporter creates the span exporter selected in the config, stdout by default.
+// Returns the file the spans are written to, if any, so that it can be closed
+func newSpanExporter(conf config.Otel) (sdktrace.SpanExporter, *os.File, error) {
+	switch conf.Exporter {
+	case ExporterOTLP:
+		opts := []otlpgrpc.Option{otlpgrpc.WithEndpoint(conf.Endpoint)}
+		if conf.Insecure {
+			opts = append(opts, otlpgrpc.WithInsecure())
+		}
+		exporter, err := otlp.NewExporter(context.Background(), otlpgrpc.NewDriver(opts...))
+		if err != nil {
+			return nil, nil, errors.NewInternalError(err).SetCode("APM.OTEL.EXPORTER_INIT_FAILED")
+		}
+		return exporter, nil, nil
+	case ExporterFile:
+		if conf.File == "" {
+			return nil, nil, errors.NewBadRequest("apm.otel.file is required by the file exporter").SetCode("APM.OTEL.EXPORTER_INIT_FAILED")
+		}
+		file, err := os.OpenFile(conf.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, errors.NewInternalError(err).SetCode("APM.OTEL.FILE_OPEN_FAILED")
+		}
+		exporter, err := stdout.NewExporter(stdout.WithWriter(file), stdout.WithoutMetricExport())
+		if err != nil {
+			file.Close()
+			return nil, nil, errors.NewInternalError(err).SetCode("APM.OTEL.EXPORTER_INIT_FAILED")
+		}
+		return exporter, file, nil
+	default:
+		exporter, err := stdout.NewExporter(stdout.WithPrettyPrint(), stdout.WithoutMetricExport())
+		if err != nil {
+			return nil, nil, errors.NewInternalError(err).SetCode("APM.OTEL.EXPORTER_INIT_FAILED")
+		}
+		return exporter, nil, nil
+	}
+}
+
+// otelErrorHandler logs the errors of the OpenTelemetry SDK eg. the spans that could not be exported
+type otelErrorHandler struct {
+}
+
+// Handle logs the error, the SDK also reports nil errors which are ignored
+func (h otelErrorHandler) Handle(err error) {
+	if err == nil {
+		return
+	}
+	log.Error("APM.OTEL.ERROR", err.Error(), log.Priority2, nil)
+}
+
+// Close exports the pending spans and shuts the tracer provider down
+func (o *OtelHandler) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), otelShutdownTimeout)
+	defer cancel()
+
+	err := o.provider.Shutdown(ctx)
+	if o.file != nil {
+		o.file.Close()
+	}
+	if err != nil {
+		return errors.NewInternalError(err).SetCode("APM.OTEL.SHUTDOWN_FAILED")
+	}
+	return nil
+}
+
+// StartTransaction starts a root server span
+func (o *OtelHandler) StartTransaction(name string) (transaction interface{}, err error) {
+	return o.start(context.Background(), name, trace.SpanKindServer), nil
+}
+
+// StartWebTransaction starts a server span continuing the trace of the traceparent header of the request, if any
+func (o *OtelHandler) StartWebTransaction(name string, w http.ResponseWriter, req *http.Request) (transaction interface{}, err error) {
+	parent := o.propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+	return o.start(parent, name, trace.SpanKindServer, semconv.HTTPServerAttributesFromHTTPRequest("", "", redactedRequest(req))...), nil
+}
+
+// StartRemoteTransaction starts a server span continuing the trace of the carrier eg. the incoming grpc metadata
+func (o *OtelHandler) StartRemoteTransaction(name string, carrier Carrier) (transaction interface{}, err error) {
+	parent := o.propagator.Extract(context.Background(), carrier)
+	return o.start(parent, name, trace.SpanKindServer, rpcAttributes(name)...), nil
+}
+
+// EndTransaction ends the span along with its error, if any
+func (o *OtelHandler) EndTransaction(transaction interface{}, er error) (err error) {
+	o.end(transaction, er)
+	return nil
+}
+
+// StartSegment starts a child span of the transaction of the context
+func (o *OtelHandler) StartSegment(ctx context.Context, segmentName string) (segment interface{}, err error) {
+	return o.start(o.parent(ctx), segmentName, trace.SpanKindInternal), nil
+}
+
+// EndSegment ends the span
+func (o *OtelHandler) EndSegment(segment interface{}) (err error) {
+	o.end(segment, nil)
+	return nil
+}
+
+// StartDataStoreSegment starts a client span of the transaction of the context for the datastore operation
+func (o *OtelHandler) StartDataStoreSegment(ctx context.Context, segmentName string, operation string, collectionName string, operations ...monitoringsystem.Operation) (datastoreSegment interface{}, err error) {
+	return o.start(o.parent(ctx), segmentName, trace.SpanKindClient,
+		semconv.DBOperationKey.String(operation),
+		attribute.String("db.collection", collectionName),
+	), nil
+}
+
+// EndDataStoreSegment ends the span
+func (o *OtelHandler) EndDataStoreSegment(segment interface{}) (err error) {
+	o.end(segment, nil)
+	return nil
+}
+
+// StartExternalSegment starts a client span of the transaction of the context.
+// The URL is either an http URL or a full grpc method eg. /proto.UserService/GetAll,
+// use Inject to propagate the trace context to the call
+func (o *OtelHandler) StartExternalSegment(ctx context.Context, URL string) (externalSegment interface{}, err error) {
+	attrs := rpcAttributes(URL)
+	if attrs == nil {
+		attrs = []attribute.KeyValue{semconv.HTTPURLKey.String(URL)}
+	}
+	return o.start(o.parent(ctx), URL, trace.SpanKindClient, attrs...), nil
+}
+
+// EndExternalSegment ends the span
+func (o *OtelHandler) EndExternalSegment(segment interface{}) error {
+	o.end(segment, nil)
+	return nil
+}
+
+// StartExternalWebSegment starts a client span of the transaction of the context
+// and injects its trace context into the headers of the request
+func (o *OtelHandler) StartExternalWebSegment(ctx context.Context, req *http.Request) (externalSegment interface{}, err error) {
+	segment := o.start(o.parent(ctx), "HTTP "+req.Method, trace.SpanKindClient, semconv.HTTPClientAttributesFromHTTPRequest(redactedRequest(req))...)
+	o.propagator.Inject(segment.ctx, propagation.HeaderCarrier(req.Header))
+	return segment, nil
+}
+
+// NoticeError records the error in the span and marks it as failed
+func (o *OtelHandler) NoticeError(transaction interface{}, err error) error {
+	s, ok := transaction.(*otelSpan)
+	if !ok || err == nil {
+		return nil
+	}
+	recordError(s.span, err)
+	return nil
+}
+
+// AddAttribute sets the attribute on the span
+func (o *OtelHandler) AddAttribute(transaction interface{}, key string, val interface{}) error {
+	s, ok := transaction.(*otelSpan)
+	if !ok {
+		return nil
+	}
+	s.span.SetAttributes(attribute.Any(key, redact.Current().Value(key, val)))
+	return nil
+}
+
+// Inject writes the trace context of the transaction or the segment into the carrier eg. the outgoing grpc metadata
+func (o *OtelHandler) Inject(segment interface{}, carrier Carrier) error {
+	s, ok := segment.(*otelSpan)
+	if !ok {
+		return nil
+	}
+	o.propagator.Inject(s.ctx, carrier)
+	return nil
+}
+
+// TraceID returns the trace ID of a transaction or a segment of the OtelHandler, empty for the other handlers.
+// It is added to the logs so that they can be correlated with the trace
+func TraceID(transaction interface{}) string {
+	s, ok := transaction.(*otelSpan)
+	if !ok || !s.span.SpanContext().HasTraceID() {
+		return ""
+	}
+	return s.span.SpanContext().TraceID().String()
+}
+
+// start starts a span as a child of the span of the parent context, if any
+func (o *OtelHandler) start(parent context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) *otelSpan {
+	ctx, span := o.tracer.Start(parent, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+	return &otelSpan{ctx: ctx, span: span}
+}
+
+// end ends the span started by start
+func (o *OtelHandler) end(transaction interface{}, err error) {
+	s, ok := transaction.(*otelSpan)
+	if !ok {
+		return
+	}
+	if err != nil {
+		recordError(s.span, err)
+	}
+	s.span.End()
+}
+
+// parent returns the context of the transaction of the context, the segments are started as its children
+func (o *OtelHandler) parent(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	if s, ok := FromContext(ctx).(*otelSpan); ok {
+		return s.ctx
+	}
+	return ctx
+}
+
+// recordError records the error as an event of the span and sets its status
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// rpcAttributes returns the rpc attributes of a full grpc method eg. /proto.UserService/GetAll,
+// nil if the name is not a grpc method
+func rpcAttributes(name string) []attribute.KeyValue {
+	if !strings.HasPrefix(name, "/") {
+		return nil
+	}
+	parts := strings.Split(name[1:], "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	return []attribute.KeyValue{
+		semconv.RPCSystemGRPC,
+		semconv.RPCServiceKey.String(parts[0]),
+		semconv.RPCMethodKey.String(parts[1]),
+	}
+}
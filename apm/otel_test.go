@@ -0,0 +1,118 @@
+package apm
+
+import (
+	"context"
+	"errors"
+	"go-boilerplate-api/config"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const incomingTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// newTestOtelHandler creates an OtelHandler exporting the spans to memory as soon as they end
+func newTestOtelHandler() (*OtelHandler, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return newOtelHandler(provider), exporter
+}
+
+func TestOtelHandlerWebTransaction(t *testing.T) {
+	handler, exporter := newTestOtelHandler()
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/user/1/info", nil)
+	req.Header.Set("traceparent", incomingTraceparent)
+	txn, err := handler.StartWebTransaction("getWithInfo", nil, req)
+	assert.Nil(t, err)
+	ctx := context.WithValue(context.Background(), TransactionKey, txn)
+
+	// The trace context of the outgoing request refers to the external segment
+	outReq, _ := http.NewRequest(http.MethodGet, "http://ratings/v1", nil)
+	external, _ := handler.StartExternalWebSegment(ctx, outReq)
+	handler.EndExternalSegment(external)
+	handler.EndTransaction(txn, errors.New("failed"))
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	client, server := spans[0], spans[1]
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.True(t, server.Parent.IsRemote())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, codes.Error, server.StatusCode)
+
+	assert.Equal(t, server.SpanContext.SpanID(), client.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindClient, client.SpanKind)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+client.SpanContext.SpanID().String()+"-01", outReq.Header.Get("traceparent"))
+
+	// The logs of the request are correlated through the trace ID
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", TraceID(txn))
+	assert.Equal(t, "", TraceID(nil))
+}
+
+func TestOtelHandlerRemoteTransaction(t *testing.T) {
+	handler, exporter := newTestOtelHandler()
+
+	incoming := metadata.Pairs("traceparent", incomingTraceparent)
+	txn, _ := handler.StartRemoteTransaction("/proto.UserService/GetWithInfo", MetadataCarrier(incoming))
+	ctx := context.WithValue(context.Background(), TransactionKey, txn)
+
+	segment, _ := handler.StartExternalSegment(ctx, "/proto.UserService/GetAll")
+	outgoing := metadata.MD{}
+	handler.Inject(segment, MetadataCarrier(outgoing))
+	handler.EndExternalSegment(segment)
+	handler.EndTransaction(txn, nil)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	client, server := spans[0], spans[1]
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, server.SpanContext.SpanID(), client.Parent.SpanID())
+	assert.Equal(t, []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-" + client.SpanContext.SpanID().String() + "-01"}, outgoing.Get("traceparent"))
+	assert.Contains(t, client.Attributes, rpcAttributes("/proto.UserService/GetAll")[1])
+}
+
+func TestOtelHandlerWithoutTransaction(t *testing.T) {
+	handler, exporter := newTestOtelHandler()
+
+	// A segment without a transaction starts a new trace
+	segment, _ := handler.StartSegment(context.Background(), "orphan")
+	handler.EndSegment(segment)
+
+	// Values that are not spans are ignored
+	assert.Nil(t, handler.EndTransaction(nil, nil))
+	assert.Nil(t, handler.AddAttribute(nil, "key", "value"))
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.False(t, spans[0].Parent.IsValid())
+}
+
+func TestRPCAttributes(t *testing.T) {
+	assert.Len(t, rpcAttributes("/proto.UserService/GetAll"), 3)
+	assert.Nil(t, rpcAttributes("http://ratings/v1"))
+	assert.Nil(t, rpcAttributes("/proto.UserService"))
+}
+
+func TestNewApmHandlerOtel(t *testing.T) {
+	conf := &config.Config{}
+	conf.APM.Handler = HandlerOtel
+	conf.APM.Otel = config.Otel{ServiceName: "go-boilerplate", Exporter: ExporterStdout, SampleRatio: 1}
+
+	handler := NewApmHandler(&staticConfig{conf: conf})
+	assert.IsType(t, &OtelHandler{}, handler)
+	assert.Nil(t, handler.(*OtelHandler).Close())
+
+	// The file exporter needs a file, the handler falls back to the no-op handler
+	conf.APM.Otel.Exporter = ExporterFile
+	assert.IsType(t, &NoopHandler{}, NewApmHandler(&staticConfig{conf: conf}))
+}
@@ -0,0 +1,247 @@
+package apm
+
+import (
+	"context"
+	"go-boilerplate-api/pkg/utils/redact"
+	"net/http"
+	"sync"
+	"time"
+
+	"stash.bms.bz/bms/monitoringsystem"
+)
+
+const (
+	// defaultRecordingCapacity is the number of transactions the recording handler keeps
+	defaultRecordingCapacity = 100
+
+	// SegmentKindSegment is the kind of a segment started with StartSegment
+	SegmentKindSegment string = "segment"
+	// SegmentKindDataStore is the kind of a segment started with StartDataStoreSegment
+	SegmentKindDataStore string = "datastore"
+	// SegmentKindExternal is the kind of a segment started with StartExternalSegment or StartExternalWebSegment
+	SegmentKindExternal string = "external"
+)
+
+// RecordedTransaction is a transaction captured by the RecordingHandler
+type RecordedTransaction struct {
+	Name       string                 `json:"name"`
+	Start      time.Time              `json:"start"`
+	DurationMs float64                `json:"durationMs"`
+	Ended      bool                   `json:"ended"`
+	Error      string                 `json:"error,omitempty"`
+	Errors     []string               `json:"errors,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Segments   []RecordedSegment      `json:"segments,omitempty"`
+}
+
+// RecordedSegment is a segment of a RecordedTransaction
+type RecordedSegment struct {
+	Kind       string    `json:"kind"`
+	Name       string    `json:"name"`
+	Operation  string    `json:"operation,omitempty"`
+	Collection string    `json:"collection,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	Start      time.Time `json:"start"`
+	DurationMs float64   `json:"durationMs"`
+	Ended      bool      `json:"ended"`
+}
+
+// RecordingHandler captures the transactions and their segments in memory.
+// It is meant for development and tests, only the latest transactions up to the capacity are kept
+type RecordingHandler struct {
+	mu           sync.Mutex
+	capacity     int
+	transactions []*RecordedTransaction
+}
+
+// recordedSegment is the handle returned when a segment is started
+type recordedSegment struct {
+	transaction *RecordedTransaction
+	index       int
+}
+
+// NewRecordingHandler creates an instance of RecordingHandler that keeps up to capacity transactions
+func NewRecordingHandler(capacity int) *RecordingHandler {
+	if capacity <= 0 {
+		capacity = defaultRecordingCapacity
+	}
+	return &RecordingHandler{capacity: capacity}
+}
+
+// Transactions returns a copy of the recorded transactions, the oldest first
+func (a *RecordingHandler) Transactions() []RecordedTransaction {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	transactions := make([]RecordedTransaction, 0, len(a.transactions))
+	for _, transaction := range a.transactions {
+		copied := *transaction
+		copied.Errors = append([]string(nil), transaction.Errors...)
+		copied.Segments = append([]RecordedSegment(nil), transaction.Segments...)
+		if transaction.Attributes != nil {
+			copied.Attributes = make(map[string]interface{}, len(transaction.Attributes))
+			for key, val := range transaction.Attributes {
+				copied.Attributes[key] = val
+			}
+		}
+		transactions = append(transactions, copied)
+	}
+	return transactions
+}
+
+// Reset removes all the recorded transactions
+func (a *RecordingHandler) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.transactions = nil
+}
+
+// StartTransaction records a new transaction
+func (a *RecordingHandler) StartTransaction(name string) (transaction interface{}, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	recorded := &RecordedTransaction{Name: name, Start: time.Now()}
+	a.transactions = append(a.transactions, recorded)
+	if len(a.transactions) > a.capacity {
+		a.transactions = a.transactions[len(a.transactions)-a.capacity:]
+	}
+	return recorded, nil
+}
+
+// StartWebTransaction records a new transaction, the request and the response are not recorded
+func (a *RecordingHandler) StartWebTransaction(name string, w http.ResponseWriter, req *http.Request) (transaction interface{}, err error) {
+	return a.StartTransaction(name)
+}
+
+// StartRemoteTransaction records a new transaction, the trace context of the carrier is not recorded
+func (a *RecordingHandler) StartRemoteTransaction(name string, carrier Carrier) (transaction interface{}, err error) {
+	return a.StartTransaction(name)
+}
+
+// EndTransaction ends the transaction along with its error, if any
+func (a *RecordingHandler) EndTransaction(transaction interface{}, er error) (err error) {
+	recorded, ok := transaction.(*RecordedTransaction)
+	if !ok {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	recorded.Ended = true
+	recorded.DurationMs = sinceMs(recorded.Start)
+	if er != nil {
+		recorded.Error = er.Error()
+	}
+	return nil
+}
+
+// StartSegment records a segment in the transaction of the context
+func (a *RecordingHandler) StartSegment(ctx context.Context, segmentName string) (segment interface{}, err error) {
+	return a.startSegment(ctx, RecordedSegment{Kind: SegmentKindSegment, Name: segmentName}), nil
+}
+
+// EndSegment ends the segment
+func (a *RecordingHandler) EndSegment(segment interface{}) (err error) {
+	a.endSegment(segment)
+	return nil
+}
+
+// StartDataStoreSegment records a datastore segment in the transaction of the context
+func (a *RecordingHandler) StartDataStoreSegment(ctx context.Context, segmentName string, operation string, collectionName string, operations ...monitoringsystem.Operation) (datastoreSegment interface{}, err error) {
+	return a.startSegment(ctx, RecordedSegment{Kind: SegmentKindDataStore, Name: segmentName, Operation: operation, Collection: collectionName}), nil
+}
+
+// EndDataStoreSegment ends the datastore segment
+func (a *RecordingHandler) EndDataStoreSegment(segment interface{}) (err error) {
+	a.endSegment(segment)
+	return nil
+}
+
+// StartExternalSegment records an external segment in the transaction of the context
+func (a *RecordingHandler) StartExternalSegment(ctx context.Context, URL string) (externalSegment interface{}, err error) {
+	return a.startSegment(ctx, RecordedSegment{Kind: SegmentKindExternal, Name: URL, URL: URL}), nil
+}
+
+// EndExternalSegment ends the external segment
+func (a *RecordingHandler) EndExternalSegment(segment interface{}) error {
+	a.endSegment(segment)
+	return nil
+}
+
+// StartExternalWebSegment records an external segment for the request in the transaction of the context
+func (a *RecordingHandler) StartExternalWebSegment(ctx context.Context, req *http.Request) (externalSegment interface{}, err error) {
+	url := redact.Current().URL(req.URL)
+	return a.startSegment(ctx, RecordedSegment{Kind: SegmentKindExternal, Name: req.Method + " " + url, URL: url}), nil
+}
+
+// NoticeError records the error in the transaction
+func (a *RecordingHandler) NoticeError(transaction interface{}, err error) error {
+	recorded, ok := transaction.(*RecordedTransaction)
+	if !ok || err == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	recorded.Errors = append(recorded.Errors, err.Error())
+	return nil
+}
+
+// AddAttribute records the attribute in the transaction
+func (a *RecordingHandler) AddAttribute(transaction interface{}, key string, val interface{}) error {
+	recorded, ok := transaction.(*RecordedTransaction)
+	if !ok {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if recorded.Attributes == nil {
+		recorded.Attributes = map[string]interface{}{}
+	}
+	recorded.Attributes[key] = redact.Current().Value(key, val)
+	return nil
+}
+
+// Inject does nothing, the recording handler does not propagate the trace context
+func (a *RecordingHandler) Inject(segment interface{}, carrier Carrier) error {
+	return nil
+}
+
+// startSegment appends the segment to the transaction of the context.
+// Returns nil if the context does not have a recorded transaction
+func (a *RecordingHandler) startSegment(ctx context.Context, segment RecordedSegment) interface{} {
+	recorded, ok := FromContext(ctx).(*RecordedTransaction)
+	if !ok {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	segment.Start = time.Now()
+	recorded.Segments = append(recorded.Segments, segment)
+	return &recordedSegment{transaction: recorded, index: len(recorded.Segments) - 1}
+}
+
+// endSegment ends the segment started by startSegment
+func (a *RecordingHandler) endSegment(segment interface{}) {
+	handle, ok := segment.(*recordedSegment)
+	if !ok {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	recorded := &handle.transaction.Segments[handle.index]
+	recorded.Ended = true
+	recorded.DurationMs = sinceMs(recorded.Start)
+}
+
+// sinceMs returns the milliseconds elapsed since the time
+func sinceMs(start time.Time) float64 {
+	return float64(time.Since(start)) / float64(time.Millisecond)
+}
@@ -0,0 +1,108 @@
+package apm
+
+import (
+	"context"
+	"errors"
+	"go-boilerplate-api/config"
+	"go-boilerplate-api/pkg/utils/redact"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+// staticConfig is an IConfig that always returns the same config
+type staticConfig struct {
+	conf *config.Config
+}
+
+func (s *staticConfig) Get() *config.Config                        { return s.conf }
+func (s *staticConfig) Subscribe(fn func(old, new *config.Config)) {}
+func (s *staticConfig) Close() error                               { return nil }
+
+func TestNewApmHandler(t *testing.T) {
+	conf := &config.Config{}
+	handlerConf := &staticConfig{conf: conf}
+
+	conf.APM.Handler = HandlerRecording
+	assert.IsType(t, &RecordingHandler{}, NewApmHandler(handlerConf))
+
+	conf.APM.Handler = HandlerNoop
+	assert.IsType(t, &NoopHandler{}, NewApmHandler(handlerConf))
+
+	conf.APM.Handler = ""
+	assert.IsType(t, &NoopHandler{}, NewApmHandler(handlerConf))
+
+	// The agent is not initialized in the tests
+	conf.APM.Handler = HandlerAgent
+	assert.IsType(t, &NoopHandler{}, NewApmHandler(handlerConf))
+}
+
+func TestRecordingHandlerTransaction(t *testing.T) {
+	handler := NewRecordingHandler(10)
+
+	txn, err := handler.StartTransaction("/proto.UserService/GetAll")
+	assert.Nil(t, err)
+	ctx := context.WithValue(context.Background(), TransactionKey, txn)
+
+	segment, _ := handler.StartSegment(ctx, "getAll")
+	req, _ := http.NewRequest(http.MethodGet, "http://ratings/v1?token=abc", nil)
+	external, _ := handler.StartExternalWebSegment(ctx, req)
+	handler.EndExternalSegment(external)
+	handler.EndSegment(segment)
+
+	handler.AddAttribute(txn, "userId", "1")
+	handler.AddAttribute(txn, "password", "hunter2")
+	handler.NoticeError(txn, errors.New("failed"))
+	handler.EndTransaction(txn, errors.New("ended with error"))
+
+	transactions := handler.Transactions()
+	assert.Len(t, transactions, 1)
+	recorded := transactions[0]
+	assert.Equal(t, "/proto.UserService/GetAll", recorded.Name)
+	assert.True(t, recorded.Ended)
+	assert.Equal(t, "ended with error", recorded.Error)
+	assert.Equal(t, []string{"failed"}, recorded.Errors)
+	// The sensitive attributes and query params are redacted
+	assert.Equal(t, map[string]interface{}{"userId": "1", "password": redact.Mask}, recorded.Attributes)
+	assert.Len(t, recorded.Segments, 2)
+	assert.Equal(t, SegmentKindSegment, recorded.Segments[0].Kind)
+	assert.Equal(t, SegmentKindExternal, recorded.Segments[1].Kind)
+	assert.Equal(t, "GET http://ratings/v1?token=%2A%2A%2A%2A", recorded.Segments[1].Name)
+	assert.Equal(t, "http://ratings/v1?token=abc", req.URL.String())
+	assert.True(t, recorded.Segments[0].Ended)
+	assert.True(t, recorded.Segments[1].Ended)
+}
+
+func TestRecordingHandlerSegmentWithoutTransaction(t *testing.T) {
+	handler := NewRecordingHandler(10)
+
+	segment, err := handler.StartSegment(context.Background(), "orphan")
+	assert.Nil(t, err)
+	assert.Nil(t, segment)
+	assert.Nil(t, handler.EndSegment(segment))
+	assert.Empty(t, handler.Transactions())
+}
+
+func TestRecordingHandlerCapacity(t *testing.T) {
+	handler := NewRecordingHandler(2)
+
+	handler.StartTransaction("first")
+	handler.StartTransaction("second")
+	handler.StartTransaction("third")
+
+	transactions := handler.Transactions()
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, "second", transactions[0].Name)
+	assert.Equal(t, "third", transactions[1].Name)
+
+	handler.Reset()
+	assert.Empty(t, handler.Transactions())
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// command is a subcommand of the binary
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+// stdout and stderr are the outputs of the commands, they are replaced in the tests
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// commands returns the subcommands in the order they are listed in the usage
+func commands() []command {
+	return []command{
+		{name: "serve", usage: "Starts the http and grpc servers (default)", run: runServe},
+		{name: "config", usage: "Prints or validates the config: config print|validate", run: runConfig},
+		{name: "routes", usage: "Lists the http routes and the grpc methods", run: runRoutes},
+		{name: "version", usage: "Prints the version and the build info", run: runVersion},
+		{name: "healthcheck", usage: "Calls the readiness endpoint, exits non-zero if the app is not ready", run: runHealthcheck},
+	}
+}
+
+// Execute runs the subcommand named by the first argument, serve is run when no subcommand is given.
+// Returns the exit code of the binary
+func Execute(args []string) int {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	for _, c := range commands() {
+		if c.name != name {
+			continue
+		}
+
+		err := c.run(args)
+		if err == flag.ErrHelp {
+			return 0
+		}
+		if err != nil {
+			newErr := errors.Get(err)
+			fmt.Fprintln(stderr, "Error: "+newErr.Description)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "Unknown command %q\n\n", name)
+	printUsage(stderr)
+	return 2
+}
+
+// printUsage prints the subcommands with their usage
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: go-boilerplate <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range commands() {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.name, c.usage)
+	}
+	tw.Flush()
+}
+
+// newFlagSet creates a flag set that reports its errors instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	return flags
+}
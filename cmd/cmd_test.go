@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"go-boilerplate-api/shared"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+// captureOutput replaces the outputs of the commands for the duration of the test
+func captureOutput(t *testing.T) (*bytes.Buffer, *bytes.Buffer) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	stdout, stderr = out, errOut
+	t.Cleanup(func() {
+		stdout, stderr = os.Stdout, os.Stderr
+	})
+	return out, errOut
+}
+
+func TestExecuteUnknownCommand(t *testing.T) {
+	_, errOut := captureOutput(t)
+
+	code := Execute([]string{"unknown"})
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut.String(), `Unknown command "unknown"`)
+	assert.Contains(t, errOut.String(), "healthcheck")
+}
+
+func TestExecuteVersion(t *testing.T) {
+	out, _ := captureOutput(t)
+	shared.VERSION = "abc123"
+	t.Cleanup(func() { shared.VERSION = "" })
+
+	code := Execute([]string{"version"})
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out.String(), "abc123")
+	assert.Contains(t, out.String(), "Go version:")
+}
+
+func TestExecuteConfigWithoutSubcommand(t *testing.T) {
+	_, errOut := captureOutput(t)
+
+	code := Execute([]string{"config"})
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut.String(), "print|validate")
+}
+
+func TestExecuteHealthcheckReady(t *testing.T) {
+	out, _ := captureOutput(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, readinessPath, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	code := Execute([]string{"healthcheck", "-url", server.URL + readinessPath})
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out.String(), "Ready")
+}
+
+func TestExecuteHealthcheckNotReady(t *testing.T) {
+	_, errOut := captureOutput(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status":"not ready"}`))
+	}))
+	defer server.Close()
+
+	code := Execute([]string{"healthcheck", "-url", server.URL + readinessPath})
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut.String(), "status 503")
+}
+
+func TestReadinessURL(t *testing.T) {
+	url, err := readinessURL(":80", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://127.0.0.1:80/health/ready", url)
+
+	url, err = readinessURL("localhost:8080", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080/health/ready", url)
+
+	url, err = readinessURL(":443", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://127.0.0.1:443/health/ready", url)
+
+	_, err = readinessURL("localhost", false)
+	assert.NotNil(t, err)
+}
+
+func TestExecuteHealthcheckTLS(t *testing.T) {
+	out, _ := captureOutput(t)
+	dir := tempDir(t)
+
+	// The server requires a client certificate signed by its CA
+	ca := newTestCert(t, nil)
+	leaf := newTestCert(t, &ca)
+	certFile, keyFile := leaf.writeFiles(t, dir, "server")
+	caFile, _ := ca.writeFiles(t, dir, "ca")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, readinessPath, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{leaf.cert.Raw}, PrivateKey: leaf.key}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	// The healthcheck reads the address and the TLS of the http server from the config of the tier
+	writeConfig(t, dir, "config/base.yaml", "appVersion: 1.0.0\n")
+	writeConfig(t, dir, "config/tier/test.yaml", `server:
+  http:
+    address: `+server.Listener.Addr().String()+`
+    tls:
+      enabled: true
+      certFile: `+certFile+`
+      keyFile: `+keyFile+`
+      caFile: `+caFile+`
+      requireClientCert: true
+`)
+	chdir(t, dir)
+
+	code := Execute([]string{"healthcheck", "-tier", "test"})
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out.String(), "Ready")
+
+	// A probe without the client certificate is rejected
+	code = Execute([]string{"healthcheck", "-url", server.URL + readinessPath})
+	assert.Equal(t, 1, code)
+}
+
+// testCert is a generated certificate along with its key
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert generates a certificate signed by the parent, a self-signed CA if there is none.
+// The leaf certificates are valid for 127.0.0.1 and usable by both the servers and the clients
+func newTestCert(t *testing.T, parent *testCert) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "go-boilerplate"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCert{cert: cert, key: key}
+}
+
+// writeFiles writes the certificate and the key in PEM files of the directory and returns their paths
+func (c testCert) writeFiles(t *testing.T, dir string, name string) (string, string) {
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	writeConfig(t, dir, name+".crt", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})))
+	writeConfig(t, dir, name+".key", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+	return certFile, keyFile
+}
+
+// writeConfig writes the file at the path relative to the directory, creating its parent directories
+func writeConfig(t *testing.T, dir string, path string, content string) {
+	file := filepath.Join(dir, path)
+	err := os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(file, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tempDir creates a temporary directory removed at the end of the test
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "cmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// chdir changes the working directory for the duration of the test
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
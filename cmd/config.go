@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"go-boilerplate-api/config"
+	"go-boilerplate-api/initiate"
+
+	"github.com/ralstan-vaz/go-errors"
+	"gopkg.in/yaml.v3"
+)
+
+// runConfig runs the config subcommands: print and validate
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return errors.NewBadRequest("config needs a subcommand: print|validate").SetCode("CMD.CONFIG.INVALID_ARGS")
+	}
+
+	switch args[0] {
+	case "print":
+		return configPrint(args[1:])
+	case "validate":
+		return configValidate(args[1:])
+	default:
+		return errors.NewBadRequest("Unknown config subcommand " + args[0] + ", expected print|validate").SetCode("CMD.CONFIG.INVALID_ARGS")
+	}
+}
+
+// configPrint prints the effective config of the tier with the sensitive values masked.
+// With -sources it prints the merged config files annotated with the file each value came from
+func configPrint(args []string) error {
+	flags := newFlagSet("config print")
+	tier := flags.String("tier", "", "tier of the config, defaults to the TIER environment variable")
+	sources := flags.Bool("sources", false, "print the merged config files annotated with the file each value came from")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	env, err := tierOrEnv(*tier)
+	if err != nil {
+		return err
+	}
+
+	if *sources {
+		dump, err := config.Dump(env, config.LocalFile)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(stdout, string(dump))
+		return nil
+	}
+
+	conf, err := config.Load(env, config.LocalFile)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := yaml.Marshal(config.Masked(conf.Get()))
+	if err != nil {
+		return errors.NewInternalError(err).SetCode("CMD.CONFIG.MARSHAL_FAILED")
+	}
+	fmt.Fprint(stdout, string(bytes))
+	return nil
+}
+
+// configValidate loads and validates the config of the tier
+func configValidate(args []string) error {
+	flags := newFlagSet("config validate")
+	tier := flags.String("tier", "", "tier of the config, defaults to the TIER environment variable")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	env, err := tierOrEnv(*tier)
+	if err != nil {
+		return err
+	}
+
+	_, err = config.Load(env, config.LocalFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(stdout, "Config of tier "+env+" is valid")
+	return nil
+}
+
+// tierOrEnv returns the tier if it is set, otherwise the tier of the environment
+func tierOrEnv(tier string) (string, error) {
+	if tier != "" {
+		return tier, nil
+	}
+	return initiate.Env()
+}
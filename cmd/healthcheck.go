@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"go-boilerplate-api/config"
+	"go-boilerplate-api/pkg/utils/tlsconfig"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+const (
+	// readinessPath is the path of the readiness endpoint
+	readinessPath string = "/health/ready"
+	// defaultHealthcheckTimeout is the time the readiness endpoint gets to respond
+	defaultHealthcheckTimeout = 3 * time.Second
+)
+
+// runHealthcheck calls the readiness endpoint of the running app and fails if it is not ready.
+// It lets the docker HEALTHCHECK work in images that do not have curl
+func runHealthcheck(args []string) error {
+	flags := newFlagSet("healthcheck")
+	url := flags.String("url", "", "readiness url, defaults to the readiness endpoint of the http address in the config")
+	tier := flags.String("tier", "", "tier of the config, defaults to the TIER environment variable")
+	timeout := flags.Duration("timeout", defaultHealthcheckTimeout, "time the readiness endpoint gets to respond")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	if *url == "" {
+		env, err := tierOrEnv(*tier)
+		if err != nil {
+			return err
+		}
+
+		// Only the http server is read so that every probe does not resolve the secrets
+		server, err := config.HTTPServer(env, config.LocalFile)
+		if err != nil {
+			return err
+		}
+
+		*url, err = readinessURL(server.Address, server.TLS.Enabled)
+		if err != nil {
+			return err
+		}
+
+		if server.TLS.Enabled {
+			reloader, err := tlsconfig.NewReloader(server.TLS.Options(), tlsconfig.DefaultReloadInterval)
+			if err != nil {
+				return err
+			}
+			defer reloader.Close()
+			// The server is verified with the CAs of its config and the probe presents its certificate,
+			// so that a server requiring client certificates accepts it
+			client.Transport = reloader.Transport()
+		}
+	}
+
+	return checkReadiness(client, *url)
+}
+
+// checkReadiness calls the url with the client and fails if it does not respond with 200
+func checkReadiness(client *http.Client, url string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return errors.NewInternalError(err).SetCode("CMD.HEALTHCHECK.REQUEST_FAILED")
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(errors.Error{
+			Kind:        errors.InternalError,
+			Code:        "CMD.HEALTHCHECK.NOT_READY",
+			Description: "Not ready, status " + strconv.Itoa(resp.StatusCode) + " : " + strings.TrimSpace(string(body)),
+		})
+	}
+
+	fmt.Fprintln(stdout, "Ready")
+	return nil
+}
+
+// readinessURL builds the url of the readiness endpoint from the address the http server listens on, https if it serves TLS.
+// An address without a host, or with a wildcard host, is called on the loopback interface
+func readinessURL(address string, tlsEnabled bool) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", errors.NewBadRequest("Invalid http address " + address).SetCode("CMD.HEALTHCHECK.INVALID_ADDRESS")
+	}
+
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+
+	scheme := "http://"
+	if tlsEnabled {
+		scheme = "https://"
+	}
+	return scheme + net.JoinHostPort(host, port) + readinessPath, nil
+}
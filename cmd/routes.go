@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"go-boilerplate-api/apis/routes"
+	"go-boilerplate-api/apm"
+	"go-boilerplate-api/config"
+	"go-boilerplate-api/pkg/clients/db"
+	"go-boilerplate-api/pkg/health"
+	"go-boilerplate-api/pkg/metrics"
+	"go-boilerplate-api/shared"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+)
+
+// runRoutes lists the http routes and the grpc methods of the app
+func runRoutes(args []string) error {
+	flags := newFlagSet("routes")
+	tier := flags.String("tier", "", "tier of the config, defaults to the TIER environment variable")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	env, err := tierOrEnv(*tier)
+	if err != nil {
+		return err
+	}
+
+	conf, err := config.Load(env, config.LocalFile)
+	if err != nil {
+		return err
+	}
+
+	// The routes are only listed, so the dependencies are not connected
+	deps := &shared.Deps{
+		Config:   conf,
+		Database: &db.Instances{},
+		Apm:      apm.NewApmHandler(conf),
+		Health:   health.NewHealth(),
+		Metrics:  metrics.NewMetrics(),
+	}
+
+	// Release mode stops gin from printing the routes as they are registered
+	gin.SetMode(gin.ReleaseMode)
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROTOCOL\tMETHOD\tPATH\tHANDLER")
+	for _, route := range routes.List(deps) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", route.Protocol, route.Method, route.Path, route.Handler)
+	}
+	return tw.Flush()
+}
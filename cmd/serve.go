@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"go-boilerplate-api/initiate"
+	log "go-boilerplate-api/pkg/utils/logger"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// runServe initializes the app and starts the servers, it returns once the servers are shut down
+func runServe(args []string) error {
+	flags := newFlagSet("serve")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	// Initializes logger
+	log.InitLogger()
+
+	// Initialize the app
+	err = initiate.Initialize()
+	if err != nil {
+		newErr := errors.Get(err)
+		// If an error is encountered while serving its critical and the app exits
+		log.Fatal(newErr.Code, newErr.Description, newErr.Source)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"go-boilerplate-api/shared"
+	"text/tabwriter"
+)
+
+// runVersion prints the version set through the ldflags along with the build info
+func runVersion(args []string) error {
+	flags := newFlagSet("version")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	build := shared.BuildInfo()
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 1, ' ', 0)
+	fmt.Fprintf(tw, "Version:\t%s\n", build.Version)
+	fmt.Fprintf(tw, "Go version:\t%s\n", build.GoVersion)
+	fmt.Fprintf(tw, "Platform:\t%s\n", build.Platform)
+	if build.Module != "" {
+		fmt.Fprintf(tw, "Module:\t%s %s\n", build.Module, build.ModuleVersion)
+	}
+	return tw.Flush()
+}
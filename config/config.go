@@ -2,61 +2,161 @@ package config
 
 import (
 	log "go-boilerplate-api/pkg/utils/logger"
-	"io/ioutil"
+	"os"
 
 	"gopkg.in/yaml.v3"
 	"stash.bms.bz/merchandise/utils"
 	"stash.bms.bz/merchandise/utils/config/ccms"
 )
 
-var configModel *Config
+// NewConfig gets the configuration based on the environment passed.
+// The tier file is deep merged over the base file and the optional local files, eg. LocalFile, are merged over the tier.
+// If reload is enabled in the config, the config files and CCMS are watched and the config is reloaded when they change
+func NewConfig(env string, localFiles ...string) (IConfig, error) {
+	ic, overridden, err := newConfigModel(env, localFiles)
+	if err != nil {
+		return nil, err
+	}
+	if len(overridden) > 0 {
+		log.Info("Config keys overridden by environment variables", overridden)
+	}
+
+	model := ic.Get()
+
+	if dump, err := Dump(env, localFiles...); err == nil {
+		log.Debug("Effective config", map[string]interface{}{"config": string(dump)})
+	}
 
-// NewConfig gets the configuration based on the environment passed
-func NewConfig(env string) (IConfig, error) {
+	if model.Reload.Enabled {
+		go ic.watch(model.Reload)
+	}
+
+	// Returns
+	return ic, nil
+}
 
-	configFile := "config/tier/" + env + ".yaml"
-	bytes, err := ioutil.ReadFile(configFile)
+// Load gets the configuration based on the environment passed, the same way NewConfig does, without watching it for changes.
+// It is meant for one off commands like validating the config
+func Load(env string, localFiles ...string) (IConfig, error) {
+	ic, _, err := newConfigModel(env, localFiles)
 	if err != nil {
 		return nil, err
 	}
+	return ic, nil
+}
+
+// newConfigModel creates an instance of IConfigModel and loads the config into it.
+// Returns the keys that were overridden by the environment
+func newConfigModel(env string, localFiles []string) (*IConfigModel, map[string]string, error) {
+	ic := &IConfigModel{layers: newLayers(env, localFiles), stop: make(chan struct{})}
+
+	secrets, err := NewSecretProviders(env)
+	if err != nil {
+		return nil, nil, err
+	}
+	ic.secrets = secrets
+
+	modTimes, err := layersModTime(ic.layers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	model, overridden, err := ic.load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ic.model.Store(model)
+	ic.modTimes = modTimes
+
+	return ic, overridden, nil
+}
+
+// Get implements the interface function for IConfig
+func (ic *IConfigModel) Get() *Config {
+	return ic.model.Load().(*Config)
+}
+
+// load merges the config files, resolves the CCMS keys and the secrets, applies the environment overrides and validates the result.
+// Returns the config along with the keys that were overridden by the environment
+func (ic *IConfigModel) load() (*Config, map[string]string, error) {
+	merged, err := mergeLayers(ic.layers)
+	if err != nil {
+		return nil, nil, err
+	}
+	bytes, err := merged.bytes()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	if env != "development" && env != "sit" && env != "docker" && env != "testing" {
-		cfg, err := InitCCMS(env)
+	if provider, ok := ic.secrets[SecretCCMS]; ok {
+		bytes, err = resolveCCMS(bytes, provider)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+	}
 
-		// anonymous function to fetch from ccms
-		getCcmsValue := func(key string) (string, error) {
-			value, err := cfg.GetKey(key)
-			if err != nil {
-				log.Fatal("CONFIG.KEY.NOT.FOUND", "Key Not Found", log.Priority1, nil, map[string]interface{}{key: err.Error()})
-				return "", err
-			}
-			return value, err
-		}
+	var model *Config
+	err = yaml.Unmarshal(bytes, &model)
+	if err != nil {
+		return nil, nil, err
+	}
+	if model == nil {
+		model = &Config{}
+	}
+
+	// Resolves the fields tagged with a secret provider eg. `ccms:"key"` or `secret:"file:/path"`
+	_, err = ResolveSecrets(model, ic.secrets)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// Binds config based on ccms and present values
-		err = utils.BindConfig(bytes, &configModel, "ccms", getCcmsValue)
+	// Overrides individual keys with the environment variables eg. APP_SERVER_HTTP_ADDRESS
+	overridden, err := ApplyEnvOverrides(model, os.LookupEnv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Validates the final config so that typos fail at startup instead of at runtime
+	err = Validate(model)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return model, overridden, nil
+}
+
+// resolveCCMS replaces the ccms keys in the config with their values from CCMS
+func resolveCCMS(bytes []byte, provider SecretProvider) ([]byte, error) {
+	// anonymous function to fetch from ccms
+	getCcmsValue := func(key string) (string, error) {
+		value, err := provider.GetKey(key)
 		if err != nil {
-			return nil, err
+			log.Error("CONFIG.KEY.NOT.FOUND", "Key Not Found", log.Priority1, nil, map[string]interface{}{key: err.Error()})
+			return "", err
 		}
-
-		return &IConfigModel{model: configModel}, nil
+		return value, err
 	}
 
-	err = yaml.Unmarshal(bytes, &configModel)
+	// Resolves ccms values into a plain map first
+	var resolved map[string]interface{}
+	err := utils.BindConfig(bytes, &resolved, "ccms", getCcmsValue)
 	if err != nil {
 		return nil, err
 	}
 
-	// Returns
-	return &IConfigModel{model: configModel}, nil
+	// Binds the resolved values through yaml so that the yaml tags and types (durations) are honoured
+	return yaml.Marshal(resolved)
 }
 
-// Get implements the interface function for IConfig
-func (ic *IConfigModel) Get() *Config {
-	return ic.model
+// usesCCMS reports if the config of the environment is resolved through CCMS
+func usesCCMS(env string) bool {
+	return env != "development" && env != "sit" && env != "docker" && env != "testing"
+}
+
+// configFile returns the path of the config file of the environment
+func configFile(env string) string {
+	return "config/tier/" + env + ".yaml"
 }
 
 // InitCCMS ...
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+const (
+	// EnvPrefix is the prefix of the environment variables that override the config
+	EnvPrefix string = "APP"
+	// maskedValue replaces sensitive values when they are logged
+	maskedValue string = "****"
+)
+
+// sensitiveKey matches the config keys whose values must not be logged
+var sensitiveKey = regexp.MustCompile(`(?i)(password|secret|token|credential|apikey|privatekey)`)
+
+// envLookup returns the value of an environment variable and whether it is set eg. os.LookupEnv
+type envLookup func(key string) (string, bool)
+
+// ApplyEnvOverrides walks the config by its yaml tags and overrides every field for which an environment variable is set.
+// The variable name is the prefix followed by the yaml path in upper snake case,
+// eg. server.http.address is overridden by APP_SERVER_HTTP_ADDRESS and user.ratingsUrl by APP_USER_RATINGS_URL.
+// Slices are read as comma separated values, maps as comma separated key=value pairs
+// and durations in the time.ParseDuration format.
+// Returns the overridden keys with their values, sensitive values are masked
+func ApplyEnvOverrides(conf *Config, lookup envLookup) (map[string]string, error) {
+	overridden := map[string]string{}
+	err := overrideStruct(reflect.ValueOf(conf).Elem(), "", lookup, overridden)
+	if err != nil {
+		return nil, err
+	}
+	return overridden, nil
+}
+
+// EnvName returns the name of the environment variable that overrides the key at the yaml path
+// eg. server.http.address -> APP_SERVER_HTTP_ADDRESS
+func EnvName(path string) string {
+	parts := []string{EnvPrefix}
+	for _, part := range strings.Split(path, ".") {
+		parts = append(parts, toUpperSnake(part))
+	}
+	return strings.Join(parts, "_")
+}
+
+// overrideStruct recursively overrides the fields of a struct
+func overrideStruct(v reflect.Value, path string, lookup envLookup, overridden map[string]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlName(field)
+		if name == "" {
+			continue
+		}
+
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		fieldValue := v.Field(i)
+		if fieldValue.Kind() == reflect.Struct {
+			err := overrideStruct(fieldValue, fieldPath, lookup, overridden)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		envName := EnvName(fieldPath)
+		value, ok := lookup(envName)
+		if !ok {
+			continue
+		}
+
+		err := setValue(fieldValue, value)
+		if err != nil {
+			return errors.NewBadRequest("Invalid value in " + envName + " for " + fieldPath + " : " + err.Error()).SetCode("CONFIG.ENV.INVALID_VALUE")
+		}
+
+		overridden[fieldPath] = maskValue(fieldPath, field, value)
+	}
+
+	return nil
+}
+
+// setValue converts the string to the kind of the field and sets it
+func setValue(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	case reflect.Slice:
+		items := []string{}
+		if value != "" {
+			items = strings.Split(value, ",")
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			err := setValue(slice.Index(i), strings.TrimSpace(item))
+			if err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(field.Type())
+		for _, item := range strings.Split(value, ",") {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			pair := strings.SplitN(item, "=", 2)
+			if len(pair) != 2 {
+				return fmt.Errorf("%q is not in the key=value format", item)
+			}
+			key := reflect.New(field.Type().Key()).Elem()
+			err := setValue(key, strings.TrimSpace(pair[0]))
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(field.Type().Elem()).Elem()
+			err = setValue(elem, strings.TrimSpace(pair[1]))
+			if err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+		}
+		field.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
+
+// yamlName returns the key of the field in the yaml, empty if the field is not bound
+func yamlName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		// unexported
+		return ""
+	}
+
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		// yaml defaults to the lowercased field name
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// maskValue masks the value if the key is sensitive or is resolved from a secret
+func maskValue(path string, field reflect.StructField, value string) string {
+	_, _, secret := secretTag(field)
+	if secret || field.Tag.Get("sensitive") == "true" || sensitiveKey.MatchString(path) {
+		return maskedValue
+	}
+	return value
+}
+
+// Masked returns a copy of the config in which the sensitive values and the secrets are masked so that it can be printed
+func Masked(conf *Config) *Config {
+	masked := *conf
+	maskStruct(reflect.ValueOf(&masked).Elem(), "")
+	return &masked
+}
+
+// maskStruct recursively masks the sensitive string fields of a struct
+func maskStruct(v reflect.Value, path string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlName(field)
+		if name == "" {
+			continue
+		}
+
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		fieldValue := v.Field(i)
+		if fieldValue.Kind() == reflect.Struct {
+			maskStruct(fieldValue, fieldPath)
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.String && fieldValue.String() != "" {
+			fieldValue.SetString(maskValue(fieldPath, field, fieldValue.String()))
+		}
+	}
+}
+
+// toUpperSnake converts a camel case key to upper snake case eg. ratingsUrl -> RATINGS_URL
+func toUpperSnake(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
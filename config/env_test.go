@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lookupFrom creates an env lookup from a map so that the tests do not depend on the actual environment
+func lookupFrom(env map[string]string) envLookup {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "APP_SERVER_HTTP_ADDRESS", EnvName("server.http.address"))
+	assert.Equal(t, "APP_USER_RATINGS_URL", EnvName("user.ratingsUrl"))
+	assert.Equal(t, "APP_SERVER_GRPC_HEALTH_CHECK_INTERVAL", EnvName("server.grpc.healthCheckInterval"))
+}
+
+func TestApplyEnvOverridesSuccess(t *testing.T) {
+	conf := &Config{}
+	conf.Server.HTTP.Address = ":80"
+
+	overridden, err := ApplyEnvOverrides(conf, lookupFrom(map[string]string{
+		"APP_SERVER_HTTP_ADDRESS":     ":8080",
+		"APP_SERVER_GRPC_REFLECTION":  "true",
+		"APP_SERVER_SHUTDOWN_TIMEOUT": "30s",
+		"APP_USER_RATINGS_URL":        "http://ratings",
+	}))
+
+	assert.Nil(t, err)
+	assert.Equal(t, ":8080", conf.Server.HTTP.Address)
+	assert.True(t, conf.Server.GRPC.Reflection)
+	assert.Equal(t, 30*time.Second, conf.Server.ShutdownTimeout)
+	assert.Equal(t, "http://ratings", conf.User.RatingsUrl)
+	assert.Equal(t, map[string]string{
+		"server.http.address":    ":8080",
+		"server.grpc.reflection": "true",
+		"server.shutdownTimeout": "30s",
+		"user.ratingsUrl":        "http://ratings",
+	}, overridden)
+}
+
+func TestApplyEnvOverridesInvalidValue(t *testing.T) {
+	conf := &Config{}
+
+	_, err := ApplyEnvOverrides(conf, lookupFrom(map[string]string{
+		"APP_SERVER_SHUTDOWN_TIMEOUT": "soon",
+	}))
+
+	assert.NotNil(t, err)
+}
+
+func TestApplyEnvOverridesMap(t *testing.T) {
+	conf := &Config{}
+
+	_, err := ApplyEnvOverrides(conf, lookupFrom(map[string]string{
+		"APP_LOG_PACKAGES": "pkg/user/rating=debug, pkg/clients=warn",
+	}))
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"pkg/user/rating": "debug", "pkg/clients": "warn"}, conf.Log.Packages)
+
+	_, err = ApplyEnvOverrides(conf, lookupFrom(map[string]string{
+		"APP_LOG_PACKAGES": "pkg/user/rating",
+	}))
+	assert.NotNil(t, err)
+}
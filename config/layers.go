@@ -0,0 +1,299 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ralstan-vaz/go-errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// BaseFile contains the config shared by all the tiers, the tier file is deep merged over it
+	BaseFile string = "config/base.yaml"
+	// LocalFile is an optional git-ignored file that is deep merged over the tier file for local overrides
+	LocalFile string = "config/local.yaml"
+
+	// deleteTag removes a key set by the files below eg. `ratingsUrl: !delete`
+	deleteTag string = "!delete"
+	// replaceTag replaces a list set by the files below instead of appending to it eg. `hosts: !replace [a, b]`
+	replaceTag string = "!replace"
+)
+
+// layer is a config file that is deep merged over the layers before it
+type layer struct {
+	file     string
+	optional bool
+}
+
+// merged is the result of deep merging the layers
+type merged struct {
+	root *yaml.Node
+	// origins maps the yaml path of every value to the file it came from
+	origins map[string]string
+}
+
+// newLayers returns the layers of the environment in the order they are merged:
+// base file, tier file and then the optional local files
+func newLayers(env string, localFiles []string) []layer {
+	layers := []layer{{file: BaseFile}, {file: configFile(env)}}
+	for _, file := range localFiles {
+		layers = append(layers, layer{file: file, optional: true})
+	}
+	return layers
+}
+
+// mergeLayers reads the layers and deep merges them in order.
+// Maps are merged key by key, scalars are replaced and lists are appended to unless tagged !replace.
+// A key tagged !delete is removed
+func mergeLayers(layers []layer) (*merged, error) {
+	result := &merged{root: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, origins: map[string]string{}}
+
+	for _, l := range layers {
+		node, err := readLayer(l)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			continue
+		}
+		mergeMapping(result.root, node, "", l.file, result.origins)
+	}
+
+	return result, nil
+}
+
+// bytes marshals the merged config
+func (m *merged) bytes() ([]byte, error) {
+	return yaml.Marshal(m.root)
+}
+
+// readLayer reads the file of the layer as a yaml mapping.
+// Returns nil if an optional file does not exist or if the file has no keys
+func readLayer(l layer) (*yaml.Node, error) {
+	bytes, err := ioutil.ReadFile(l.file)
+	if err != nil {
+		if os.IsNotExist(err) && l.optional {
+			return nil, nil
+		}
+		return nil, errors.NewInternalError(err).SetCode("CONFIG.FILE.READ_FAILED")
+	}
+
+	var doc yaml.Node
+	err = yaml.Unmarshal(bytes, &doc)
+	if err != nil {
+		return nil, errors.NewBadRequest(l.file + " : " + err.Error()).SetCode("CONFIG.FILE.PARSE_FAILED")
+	}
+	// Files that are empty or only contain comments do not change the config
+	if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.NewBadRequest(l.file + " : the config must be a map").SetCode("CONFIG.FILE.PARSE_FAILED")
+	}
+
+	return root, nil
+}
+
+// mergeMapping deep merges the src mapping into the dst mapping
+func mergeMapping(dst *yaml.Node, src *yaml.Node, path string, file string, origins map[string]string) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		keyPath := joinPath(path, key.Value)
+		index := mappingIndex(dst, key.Value)
+
+		if value.Tag == deleteTag {
+			if index >= 0 {
+				dst.Content = append(dst.Content[:index], dst.Content[index+2:]...)
+			}
+			deleteOrigins(origins, keyPath)
+			continue
+		}
+
+		if index < 0 {
+			value = cleanNode(value)
+			dst.Content = append(dst.Content, key, value)
+			setOrigins(origins, keyPath, value, file)
+			continue
+		}
+
+		existing := dst.Content[index+1]
+		switch {
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeMapping(existing, value, keyPath, file, origins)
+		case existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode && value.Tag != replaceTag:
+			// Lists are appended to unless they are explicitly replaced
+			offset := len(existing.Content)
+			value = cleanNode(value)
+			existing.Content = append(existing.Content, value.Content...)
+			for j, item := range value.Content {
+				setOrigins(origins, indexPath(keyPath, offset+j), item, file)
+			}
+		default:
+			value = cleanNode(value)
+			dst.Content[index+1] = value
+			deleteOrigins(origins, keyPath)
+			setOrigins(origins, keyPath, value, file)
+		}
+	}
+}
+
+// cleanNode removes the merge tags from the node and the keys tagged !delete from the maps within it
+func cleanNode(node *yaml.Node) *yaml.Node {
+	if node.Tag == deleteTag || node.Tag == replaceTag {
+		node.Tag = ""
+	}
+
+	if node.Kind == yaml.MappingNode {
+		content := make([]*yaml.Node, 0, len(node.Content))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i+1].Tag == deleteTag {
+				continue
+			}
+			content = append(content, node.Content[i], cleanNode(node.Content[i+1]))
+		}
+		node.Content = content
+	}
+
+	if node.Kind == yaml.SequenceNode {
+		for _, item := range node.Content {
+			cleanNode(item)
+		}
+	}
+
+	return node
+}
+
+// mappingIndex returns the index of the key in the mapping, -1 if it does not exist
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// setOrigins records the file as the origin of every value within the node
+func setOrigins(origins map[string]string, path string, node *yaml.Node, file string) {
+	switch {
+	case node.Kind == yaml.MappingNode && len(node.Content) > 0:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			setOrigins(origins, joinPath(path, node.Content[i].Value), node.Content[i+1], file)
+		}
+	case node.Kind == yaml.SequenceNode && len(node.Content) > 0:
+		for i, item := range node.Content {
+			setOrigins(origins, indexPath(path, i), item, file)
+		}
+	default:
+		origins[path] = file
+	}
+}
+
+// deleteOrigins removes the origins of the path and of every value within it
+func deleteOrigins(origins map[string]string, path string) {
+	for key := range origins {
+		if key == path || strings.HasPrefix(key, path+".") || strings.HasPrefix(key, path+"[") {
+			delete(origins, key)
+		}
+	}
+}
+
+// annotate sets the origin of every value as its line comment
+func annotate(node *yaml.Node, path string, origins map[string]string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			annotate(node.Content[i+1], joinPath(path, node.Content[i].Value), origins)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			annotate(item, indexPath(path, i), origins)
+		}
+	}
+
+	if origin, ok := origins[path]; ok {
+		node.LineComment = "from " + origin
+	}
+}
+
+// Dump returns the effective config of the environment, after merging the layers, as yaml.
+// Every value is annotated with the file it came from. CCMS keys are not resolved
+func Dump(env string, localFiles ...string) ([]byte, error) {
+	result, err := mergeLayers(newLayers(env, localFiles))
+	if err != nil {
+		return nil, err
+	}
+
+	annotate(result.root, "", result.origins)
+	return result.bytes()
+}
+
+// HTTPServer returns server.http of the environment, its address and TLS, from the layers and the environment variables.
+// The secrets are not resolved, so that the healthcheck reading it does not depend on the secret providers eg. CCMS
+func HTTPServer(env string, localFiles ...string) (HTTP, error) {
+	return httpServer(newLayers(env, localFiles), os.LookupEnv)
+}
+
+// httpServer reads server.http from the merged layers, the environment variables override its keys
+func httpServer(layers []layer, lookup envLookup) (HTTP, error) {
+	result, err := mergeLayers(layers)
+	if err != nil {
+		return HTTP{}, err
+	}
+	bytes, err := result.bytes()
+	if err != nil {
+		return HTTP{}, err
+	}
+
+	var model struct {
+		Server struct {
+			HTTP HTTP `yaml:"http"`
+		} `yaml:"server"`
+	}
+	err = yaml.Unmarshal(bytes, &model)
+	if err != nil {
+		return HTTP{}, errors.NewBadRequest("Invalid config : " + err.Error()).SetCode("CONFIG.FILE.PARSE_FAILED")
+	}
+	err = overrideStruct(reflect.ValueOf(&model).Elem(), "", lookup, map[string]string{})
+	if err != nil {
+		return HTTP{}, err
+	}
+	if model.Server.HTTP.Address == "" {
+		return HTTP{}, errors.NewBadRequest("server.http.address is not set").SetCode("CONFIG.KEY.NOT.FOUND")
+	}
+	return model.Server.HTTP, nil
+}
+
+// layersModTime returns the modification time of every layer, missing optional files are skipped
+func layersModTime(layers []layer) (map[string]time.Time, error) {
+	modTimes := map[string]time.Time{}
+	for _, l := range layers {
+		info, err := os.Stat(l.file)
+		if err != nil {
+			if os.IsNotExist(err) && l.optional {
+				continue
+			}
+			return nil, errors.NewInternalError(err).SetCode("CONFIG.FILE.STAT_FAILED")
+		}
+		modTimes[l.file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func indexPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
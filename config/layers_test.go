@@ -0,0 +1,172 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const baseLayer = `
+appVersion: 1.0.0
+server:
+  http:
+    address: :80
+  drainDelay: 0s
+hosts: [a, b]
+tags: [x]
+debug: true
+`
+
+const tierLayer = `
+server:
+  http:
+    address: :8080
+  drainDelay: 5s
+hosts: [c]
+tags: !replace [y, z]
+debug: !delete
+`
+
+// writeLayers writes the contents to temporary files and returns them as layers in the same order
+func writeLayers(t *testing.T, contents ...string) []layer {
+	dir, err := ioutil.TempDir("", "layers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	layers := []layer{}
+	for i, content := range contents {
+		file := filepath.Join(dir, string(rune('a'+i))+".yaml")
+		err = ioutil.WriteFile(file, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		layers = append(layers, layer{file: file})
+	}
+	return layers
+}
+
+func TestMergeLayers(t *testing.T) {
+	layers := writeLayers(t, baseLayer, tierLayer)
+
+	result, err := mergeLayers(layers)
+	assert.Nil(t, err)
+
+	var merged map[string]interface{}
+	err = result.root.Decode(&merged)
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"appVersion": "1.0.0",
+		"server": map[string]interface{}{
+			"http":       map[string]interface{}{"address": ":8080"},
+			"drainDelay": "5s",
+		},
+		"hosts": []interface{}{"a", "b", "c"},
+		"tags":  []interface{}{"y", "z"},
+	}, merged)
+
+	assert.Equal(t, map[string]string{
+		"appVersion":          layers[0].file,
+		"server.http.address": layers[1].file,
+		"server.drainDelay":   layers[1].file,
+		"hosts[0]":            layers[0].file,
+		"hosts[1]":            layers[0].file,
+		"hosts[2]":            layers[1].file,
+		"tags[0]":             layers[1].file,
+		"tags[1]":             layers[1].file,
+	}, result.origins)
+}
+
+func TestMergeLayersStripsTags(t *testing.T) {
+	layers := writeLayers(t, baseLayer, tierLayer)
+
+	result, err := mergeLayers(layers)
+	assert.Nil(t, err)
+
+	bytes, err := result.bytes()
+	assert.Nil(t, err)
+	assert.NotContains(t, string(bytes), replaceTag)
+	assert.NotContains(t, string(bytes), deleteTag)
+}
+
+func TestMergeLayersOptionalMissing(t *testing.T) {
+	layers := writeLayers(t, baseLayer)
+	layers = append(layers, layer{file: layers[0].file + ".missing", optional: true})
+
+	result, err := mergeLayers(layers)
+	assert.Nil(t, err)
+	assert.Equal(t, layers[0].file, result.origins["appVersion"])
+}
+
+func TestMergeLayersRequiredMissing(t *testing.T) {
+	layers := writeLayers(t, baseLayer)
+	layers = append(layers, layer{file: layers[0].file + ".missing"})
+
+	_, err := mergeLayers(layers)
+	assert.NotNil(t, err)
+}
+
+func TestMergeLayersInvalid(t *testing.T) {
+	layers := writeLayers(t, baseLayer, "- not a map")
+
+	_, err := mergeLayers(layers)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), layers[1].file))
+}
+
+func TestAnnotate(t *testing.T) {
+	layers := writeLayers(t, baseLayer, tierLayer)
+
+	result, err := mergeLayers(layers)
+	assert.Nil(t, err)
+	annotate(result.root, "", result.origins)
+
+	bytes, err := yaml.Marshal(result.root)
+	assert.Nil(t, err)
+	assert.Contains(t, string(bytes), "appVersion: 1.0.0 # from "+layers[0].file)
+	assert.Contains(t, string(bytes), "address: :8080 # from "+layers[1].file)
+}
+
+func TestMergeLayersNestedDelete(t *testing.T) {
+	layers := writeLayers(t, baseLayer, "server:\n  http: !delete\n")
+
+	result, err := mergeLayers(layers)
+	assert.Nil(t, err)
+
+	var merged map[string]interface{}
+	err = result.root.Decode(&merged)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"drainDelay": "0s"}, merged["server"])
+	assert.NotContains(t, result.origins, "server.http.address")
+}
+
+func TestHTTPServer(t *testing.T) {
+	layers := writeLayers(t, baseLayer, tierLayer)
+	noEnv := func(string) (string, bool) { return "", false }
+
+	// The address of the tier is read without loading the whole config
+	server, err := httpServer(layers, noEnv)
+	assert.Nil(t, err)
+	assert.Equal(t, ":8080", server.Address)
+	assert.False(t, server.TLS.Enabled)
+
+	// The environment variables override its keys
+	env := map[string]string{"APP_SERVER_HTTP_ADDRESS": ":9090", "APP_SERVER_HTTP_TLS_ENABLED": "true"}
+	server, err = httpServer(layers, func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, ":9090", server.Address)
+	assert.True(t, server.TLS.Enabled)
+
+	_, err = httpServer(writeLayers(t, "appVersion: 1.0.0\n"), noEnv)
+	assert.NotNil(t, err)
+}
@@ -1,40 +1,228 @@
 package config
 
+import (
+	"go-boilerplate-api/pkg/utils/tlsconfig"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // IConfig is an interface that helps you interact with the config module
 type IConfig interface {
+	// Get returns the current config, the returned config must not be modified
 	Get() *Config
+	// Subscribe registers a function that is notified when the config is reloaded
+	Subscribe(fn func(old, new *Config))
+	// Close stops watching the config for changes
+	Close() error
 }
 
 // IConfigModel used as the instance to the IConfig Interface
 type IConfigModel struct {
-	model *Config
+	// model holds the current *Config, it is swapped atomically on reload
+	model atomic.Value
+	// layers are the config files that are deep merged, modTimes holds their last seen modification time
+	layers   []layer
+	modTimes map[string]time.Time
+	// secrets resolves the ccms keys in the config files and the secret fields
+	secrets SecretProviders
+
+	mu          sync.Mutex
+	subscribers []func(old, new *Config)
+
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 //Config is a model that is used to pass the configuration through out the project
 type Config struct {
-	AppVersion string `yaml:"appVersion"`
-	Server     Server `yaml:"server"`
-	User       User   `yaml:"user"`
+	AppVersion string    `yaml:"appVersion" validate:"required"`
+	Server     Server    `yaml:"server"`
+	Reload     Reload    `yaml:"reload"`
+	APM        APM       `yaml:"apm"`
+	Log        Log       `yaml:"log"`
+	Redaction  Redaction `yaml:"redaction"`
+	User       User      `yaml:"user"`
+}
+
+// Redaction contains the policy redacting the sensitive data from the logs, the apm attributes,
+// the query strings and the panic responses, it is applied again when the config is reloaded
+type Redaction struct {
+	// Fields are the names of the sensitive fields, query params and attributes whose values are masked,
+	// matched ignoring the case, - and _
+	Fields []string `yaml:"fields"`
+	// Patterns are regular expressions, the parts of the strings that match them are masked eg. bearer tokens
+	Patterns []string `yaml:"patterns" validate:"dive,regexp"`
+	// ExposeStackTrace sends the error and the stack trace of a panic to the client, only for the development tiers
+	ExposeStackTrace bool `yaml:"exposeStackTrace"`
+}
+
+// Log contains the logging related configurations, the levels are applied again when the config is reloaded
+type Log struct {
+	// Level is the lowest level that is logged: debug, info, warn or error, errors are always logged
+	Level string `yaml:"level" validate:"omitempty,oneof=debug info warn error"`
+	// Packages overrides the level of the packages, keyed by their path within the module eg. pkg/user/rating,
+	// the level of a package also applies to the packages under it
+	Packages map[string]string `yaml:"packages" validate:"omitempty,dive,oneof=debug info warn error"`
+	// OverrideTTL is how long the levels changed at runtime, through the admin endpoint or SIGUSR1,
+	// are kept before they revert to the configured ones
+	OverrideTTL time.Duration `yaml:"overrideTTL" validate:"gte=0"`
+	Access      AccessLog     `yaml:"access"`
+}
+
+// AccessLog contains the configurations of the access logs of the http and the grpc requests
+type AccessLog struct {
+	Enabled bool `yaml:"enabled"`
+	// SuccessSampleRate is the fraction of the successful requests that are logged, the failed requests are always logged whatever log.level is
+	SuccessSampleRate float64 `yaml:"successSampleRate" validate:"gte=0,lte=1"`
+	// Exclude are the http paths or route templates and the grpc full methods that are never logged eg. /metrics
+	Exclude []string `yaml:"exclude"`
+}
+
+// APM contains the apm related configurations
+type APM struct {
+	// Handler selects the apm implementation: agent reports to the apm server, otel exports OpenTelemetry spans,
+	// recording keeps the transactions in memory and noop, the default, discards them
+	Handler string `yaml:"handler" validate:"omitempty,oneof=agent otel recording noop"`
+	Otel    Otel   `yaml:"otel"`
+}
+
+// Otel contains the OpenTelemetry related configurations, used when the apm handler is otel.
+// The exporter and the sampler are created once at startup, a change in them needs a restart
+type Otel struct {
+	ServiceName string `yaml:"serviceName"`
+	// Exporter selects where the spans are sent: otlp sends them to a collector over grpc,
+	// stdout writes them to the standard output and file appends them to File
+	Exporter string `yaml:"exporter" validate:"omitempty,oneof=otlp stdout file"`
+	// Endpoint is the host:port of the OTLP collector
+	Endpoint string `yaml:"endpoint" validate:"omitempty,hostname_port"`
+	Insecure bool   `yaml:"insecure"`
+	// File is the path the spans are appended to by the file exporter
+	File string `yaml:"file"`
+	// SampleRatio is the fraction of the traces started by this app that are sampled,
+	// the traces started upstream follow the decision of their parent
+	SampleRatio float64 `yaml:"sampleRatio" validate:"gte=0,lte=1"`
+}
+
+// Reload contains the config hot reload related configurations.
+// Server addresses are read once at startup, a change in them needs a restart
+type Reload struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is how often the config file is checked for changes
+	Interval time.Duration `yaml:"interval" validate:"gte=0"`
+	// CCMSInterval is how often the CCMS keys are refreshed
+	CCMSInterval time.Duration `yaml:"ccmsInterval" validate:"gte=0"`
 }
 
 // Server contains server related configurations
 type Server struct {
-	GRPC GRPC `yaml:"grpc"`
-	HTTP HTTP `yaml:"http"`
+	GRPC  GRPC  `yaml:"grpc"`
+	HTTP  HTTP  `yaml:"http"`
+	Admin Admin `yaml:"admin"`
+	// ShutdownTimeout is the time the servers get to drain in-flight requests on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" validate:"gte=0"`
+	// DrainDelay is the time the servers keep serving while reporting not ready on shutdown,
+	// so that the load balancers stop routing new requests before the servers stop accepting them
+	DrainDelay time.Duration `yaml:"drainDelay" validate:"gte=0"`
 }
 
 // HTTP contains http related configurations
 type HTTP struct {
-	Address string `yaml:"address"`
+	Address string `yaml:"address" validate:"required,hostname_port"`
+	// Mode is the mode of gin, debug prints the routes and the warnings of gin, release by default
+	Mode string `yaml:"mode" validate:"omitempty,oneof=debug release test"`
+	// TrustedProxies are the IPs or CIDRs of the proxies trusted to set the X-Forwarded-For and X-Real-Ip headers,
+	// the headers are ignored when there are none and the client IP is the address of the connection
+	TrustedProxies []string `yaml:"trustedProxies" validate:"dive,cidr|ip"`
+	// MaxMultipartMemory is the number of bytes of a multipart form kept in memory, the rest is stored in temporary files
+	MaxMultipartMemory int64 `yaml:"maxMultipartMemory" validate:"gte=0"`
+	// RedirectTrailingSlash redirects a path with or without the trailing slash to the route that matches eg. /ping to /ping/
+	RedirectTrailingSlash bool `yaml:"redirectTrailingSlash"`
+	// RedirectFixedPath redirects a path to the route that matches it once cleaned and ignoring the case eg. /USERS/1 to /users/1
+	RedirectFixedPath bool `yaml:"redirectFixedPath"`
+	// The timeouts of the server, 0 for no timeout. ReadTimeout covers reading the whole request, WriteTimeout
+	// writing the response from the end of the headers and IdleTimeout how long a keep-alive connection waits for the next request
+	ReadTimeout  time.Duration `yaml:"readTimeout" validate:"gte=0"`
+	WriteTimeout time.Duration `yaml:"writeTimeout" validate:"gte=0"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout" validate:"gte=0"`
+	TLS          ServerTLS     `yaml:"tls"`
+}
+
+// Admin contains the configurations of the admin server serving the diagnostics eg. pprof, kept off the public http server
+type Admin struct {
+	// Address is the address the admin server binds to eg. 127.0.0.1:6060 so that it is only reachable locally,
+	// the admin server is not started if it is empty
+	Address string `yaml:"address" validate:"omitempty,hostname_port"`
+	// Token is the bearer token the requests to the admin server must carry, the requests are not authenticated if it is empty
+	Token string `yaml:"token" sensitive:"true"`
 }
 
 // GRPC contains GRPC related configurations
 type GRPC struct {
-	Address string `yaml:"address"`
+	Address string `yaml:"address" validate:"required,hostname_port"`
+	// Reflection enables the server reflection service
+	Reflection bool `yaml:"reflection"`
+	// HealthCheckInterval is how often the serving status of the grpc.health.v1 service is refreshed
+	HealthCheckInterval time.Duration `yaml:"healthCheckInterval" validate:"gte=0"`
+	TLS                 ServerTLS     `yaml:"tls"`
+}
+
+// TLS contains the TLS configurations of a server or of a client, the connections are in plaintext unless it is enabled.
+// The files are read once at startup and then reloaded when they change, checked every reload.interval
+type TLS struct {
+	Enabled bool `yaml:"enabled"`
+	// CertFile and KeyFile are the PEM files of the certificate and of its private key,
+	// required for a server and presented by a client to the servers requiring mTLS
+	CertFile string `yaml:"certFile" validate:"required_with=KeyFile"`
+	KeyFile  string `yaml:"keyFile" validate:"required_with=CertFile"`
+	// CAFile is the PEM file of the CAs verifying the peer. A server with CAs verifies the client certificates (mTLS),
+	// a client without CAs verifies the server with the CAs of the system
+	CAFile string `yaml:"caFile"`
+	// RequireClientCert makes a server with CAs reject the clients without a certificate,
+	// the clients are only verified when they present one otherwise
+	RequireClientCert bool `yaml:"requireClientCert"`
+	// ServerName overrides the name a client verifies the server certificate against, the host it connects to by default.
+	// For the http server, it is the name the healthcheck verifies the server certificate against
+	ServerName string `yaml:"serverName"`
+	// MinVersion is the minimum TLS version, 1.2 by default
+	MinVersion string `yaml:"minVersion" validate:"omitempty,oneof=1.2 1.3"`
+}
+
+// ServerTLS contains the TLS configurations of a server, the fields are the ones of TLS
+// but the certificate and its key are required once it is enabled, so that config validate catches them missing
+type ServerTLS struct {
+	Enabled           bool   `yaml:"enabled"`
+	CertFile          string `yaml:"certFile" validate:"required_if=Enabled true,required_with=KeyFile"`
+	KeyFile           string `yaml:"keyFile" validate:"required_if=Enabled true,required_with=CertFile"`
+	CAFile            string `yaml:"caFile"`
+	RequireClientCert bool   `yaml:"requireClientCert"`
+	ServerName        string `yaml:"serverName"`
+	MinVersion        string `yaml:"minVersion" validate:"omitempty,oneof=1.2 1.3"`
+}
+
+// Options returns the options of the TLS config
+func (t ServerTLS) Options() tlsconfig.Options {
+	return TLS(t).Options()
+}
+
+// Options returns the options of the TLS config
+func (t TLS) Options() tlsconfig.Options {
+	return tlsconfig.Options{
+		CertFile:          t.CertFile,
+		KeyFile:           t.KeyFile,
+		CAFile:            t.CAFile,
+		RequireClientCert: t.RequireClientCert,
+		ServerName:        t.ServerName,
+		MinVersion:        t.MinVersion,
+	}
 }
 
 // User contains user pkg specific config
 type User struct {
-	RatingsUrl    string `yaml:"ratingsUrl"`
-	FavouritesUrl string `yaml:"favouritesUrl"`
+	RatingsUrl    string `yaml:"ratingsUrl" validate:"required,url"`
+	FavouritesUrl string `yaml:"favouritesUrl" validate:"required,hostname_port"`
+	// RatingsTLS is the TLS config of the http client calling the ratings API, an https URL is verified with the CAs of the system without it
+	RatingsTLS TLS `yaml:"ratingsTls"`
+	// FavouritesTLS is the TLS config of the grpc connection to the favourites service
+	FavouritesTLS TLS `yaml:"favouritesTls"`
 }
@@ -0,0 +1,117 @@
+package config
+
+import (
+	log "go-boilerplate-api/pkg/utils/logger"
+	"reflect"
+	"time"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+const (
+	// defaultReloadInterval is used when reload is enabled without an interval
+	defaultReloadInterval = 10 * time.Second
+	// defaultCCMSRefreshInterval is used when reload is enabled without a CCMS refresh interval
+	defaultCCMSRefreshInterval = 5 * time.Minute
+)
+
+// Subscribe registers a function that is called with the old and the new config every time the config is reloaded.
+// Subscribers are called one after the other from the goroutine that reloads the config
+func (ic *IConfigModel) Subscribe(fn func(old, new *Config)) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	ic.subscribers = append(ic.subscribers, fn)
+}
+
+// Close stops watching the config for changes
+func (ic *IConfigModel) Close() error {
+	ic.stopOnce.Do(func() {
+		close(ic.stop)
+	})
+	return nil
+}
+
+// watch polls the modification time of the config files and periodically refreshes the CCMS keys.
+// It runs until Close is called
+func (ic *IConfigModel) watch(reload Reload) {
+	interval := reload.Interval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	fileTicker := time.NewTicker(interval)
+	defer fileTicker.Stop()
+
+	// CCMS is refreshed only if there is a provider for it
+	var ccmsRefresh <-chan time.Time
+	if ic.secrets[SecretCCMS] != nil {
+		ccmsInterval := reload.CCMSInterval
+		if ccmsInterval <= 0 {
+			ccmsInterval = defaultCCMSRefreshInterval
+		}
+		ccmsTicker := time.NewTicker(ccmsInterval)
+		defer ccmsTicker.Stop()
+		ccmsRefresh = ccmsTicker.C
+	}
+
+	for {
+		select {
+		case <-ic.stop:
+			return
+		case <-fileTicker.C:
+			modTimes, err := layersModTime(ic.layers)
+			if err != nil {
+				log.Error(errors.Get(err).Code, "Config files could not be checked for changes", log.Priority2, errors.Get(err).Source)
+				continue
+			}
+			if modTimesEqual(modTimes, ic.modTimes) {
+				continue
+			}
+			ic.modTimes = modTimes
+			ic.reload("file")
+		case <-ccmsRefresh:
+			ic.reload("ccms")
+		}
+	}
+}
+
+// reload loads and validates the config and atomically swaps it with the current one.
+// The current config is kept if the new config is invalid
+func (ic *IConfigModel) reload(trigger string) {
+	newModel, _, err := ic.load()
+	if err != nil {
+		newErr := errors.Get(err)
+		log.Error(newErr.Code, "Config reload failed, the current config is kept : "+newErr.Description, log.Priority2, newErr.Source)
+		return
+	}
+
+	oldModel := ic.Get()
+	if reflect.DeepEqual(oldModel, newModel) {
+		return
+	}
+
+	ic.model.Store(newModel)
+	log.Info("Config reloaded", map[string]interface{}{"trigger": trigger})
+
+	ic.mu.Lock()
+	subscribers := make([]func(old, new *Config), len(ic.subscribers))
+	copy(subscribers, ic.subscribers)
+	ic.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(oldModel, newModel)
+	}
+}
+
+// modTimesEqual reports if the files and their modification times are the same.
+// An optional file that is created or removed is also a change
+func modTimesEqual(a map[string]time.Time, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for file, modTime := range a {
+		if !modTime.Equal(b[file]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const reloadTestConfig = `
+appVersion: 1.0.0
+server:
+  grpc:
+    address: :5001
+  http:
+    address: :80
+user:
+  ratingsUrl: "%s"
+  favouritesUrl: ":5001"
+`
+
+// newTestConfig writes the config to a temporary file and loads it
+func newTestConfig(t *testing.T, ratingsURL string) (*IConfigModel, string) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	file := filepath.Join(dir, "testing.yaml")
+	writeTestConfig(t, file, ratingsURL)
+
+	ic := &IConfigModel{layers: []layer{{file: file}}, stop: make(chan struct{})}
+	model, _, err := ic.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ic.model.Store(model)
+
+	return ic, file
+}
+
+func writeTestConfig(t *testing.T, file string, ratingsURL string) {
+	content := []byte(strings.Replace(reloadTestConfig, "%s", ratingsURL, 1))
+	err := ioutil.WriteFile(file, content, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadNotifiesSubscribers(t *testing.T) {
+	ic, file := newTestConfig(t, "http://ratings/v1")
+
+	var oldURL, newURL string
+	ic.Subscribe(func(old, new *Config) {
+		oldURL = old.User.RatingsUrl
+		newURL = new.User.RatingsUrl
+	})
+
+	writeTestConfig(t, file, "http://ratings/v2")
+	ic.reload("file")
+
+	assert.Equal(t, "http://ratings/v1", oldURL)
+	assert.Equal(t, "http://ratings/v2", newURL)
+	assert.Equal(t, "http://ratings/v2", ic.Get().User.RatingsUrl)
+}
+
+func TestReloadKeepsConfigWhenInvalid(t *testing.T) {
+	ic, file := newTestConfig(t, "http://ratings/v1")
+
+	notified := false
+	ic.Subscribe(func(old, new *Config) {
+		notified = true
+	})
+
+	writeTestConfig(t, file, "")
+	ic.reload("file")
+
+	assert.False(t, notified)
+	assert.Equal(t, "http://ratings/v1", ic.Get().User.RatingsUrl)
+}
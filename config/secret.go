@@ -0,0 +1,246 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ralstan-vaz/go-errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SecretCCMS is the provider of the fields tagged `ccms:"key"` or `secret:"ccms:key"`
+	SecretCCMS string = "ccms"
+	// SecretFile is the provider of the fields tagged `secret:"file:/path"`
+	SecretFile string = "file"
+	// SecretEnv is the provider of the fields tagged `secret:"env:NAME"`
+	SecretEnv string = "env"
+
+	// SecretsDir is the directory the relative file secrets are read from eg. a mounted kubernetes secret
+	SecretsDir string = "/var/run/secrets/app"
+	// LocalSecretsFile is an optional git-ignored yaml map of keys to values that stands in for CCMS in the local tiers
+	LocalSecretsFile string = "config/secrets.local.yaml"
+)
+
+// SecretProvider resolves the value of a secret by its key. ccms.Provider is a SecretProvider
+type SecretProvider interface {
+	GetKey(key string) (string, error)
+}
+
+// SecretProviders maps the provider names used in the tags to the providers
+type SecretProviders map[string]SecretProvider
+
+// NewSecretProviders creates the secret providers of the environment.
+// The tiers that do not use CCMS get an in-process stand-in seeded from LocalSecretsFile
+func NewSecretProviders(env string) (SecretProviders, error) {
+	providers := SecretProviders{
+		SecretFile: FileSecrets{Dir: SecretsDir},
+		SecretEnv:  NewEnvSecrets(),
+	}
+
+	if usesCCMS(env) {
+		provider, err := InitCCMS(env)
+		if err != nil {
+			return nil, err
+		}
+		providers[SecretCCMS] = provider
+		return providers, nil
+	}
+
+	local, err := loadMemorySecrets(LocalSecretsFile)
+	if err != nil {
+		return nil, err
+	}
+	providers[SecretCCMS] = local
+
+	return providers, nil
+}
+
+// FileSecrets reads secrets that are mounted as files, one secret per file.
+// Relative keys are read from Dir
+type FileSecrets struct {
+	Dir string
+}
+
+// GetKey returns the content of the file without the trailing new line
+func (f FileSecrets) GetKey(key string) (string, error) {
+	file := key
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(f.Dir, file)
+	}
+
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", errors.NewInternalError(err).SetCode("CONFIG.SECRET.FILE_READ_FAILED")
+	}
+	return strings.TrimRight(string(bytes), "\r\n"), nil
+}
+
+// EnvSecrets reads secrets from the environment variables
+type EnvSecrets struct {
+	lookup envLookup
+}
+
+// NewEnvSecrets creates an instance of EnvSecrets that reads the process environment
+func NewEnvSecrets() *EnvSecrets {
+	return &EnvSecrets{lookup: os.LookupEnv}
+}
+
+// GetKey returns the value of the environment variable
+func (e *EnvSecrets) GetKey(key string) (string, error) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return "", errors.NewNotFound("Environment variable " + key + " is not set").SetCode("CONFIG.SECRET.NOT_FOUND")
+	}
+	return value, nil
+}
+
+// MemorySecrets is an in-process key/value store that stands in for CCMS in the local tiers and the tests
+type MemorySecrets struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewMemorySecrets creates an instance of MemorySecrets holding a copy of the values
+func NewMemorySecrets(values map[string]string) *MemorySecrets {
+	m := &MemorySecrets{values: map[string]string{}}
+	for key, value := range values {
+		m.values[key] = value
+	}
+	return m
+}
+
+// Set sets the value of the key, it is picked up on the next config reload
+func (m *MemorySecrets) Set(key string, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+}
+
+// GetKey returns the value of the key
+func (m *MemorySecrets) GetKey(key string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	value, ok := m.values[strings.TrimSpace(key)]
+	if !ok {
+		return "", errors.NewNotFound("Key " + key + " is not set").SetCode("CONFIG.SECRET.NOT_FOUND")
+	}
+	return value, nil
+}
+
+// loadMemorySecrets creates an instance of MemorySecrets from a yaml map, a missing file gives an empty store
+func loadMemorySecrets(file string) (*MemorySecrets, error) {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewMemorySecrets(nil), nil
+		}
+		return nil, errors.NewInternalError(err).SetCode("CONFIG.FILE.READ_FAILED")
+	}
+
+	var values map[string]string
+	err = yaml.Unmarshal(bytes, &values)
+	if err != nil {
+		return nil, errors.NewBadRequest(file + " : " + err.Error()).SetCode("CONFIG.FILE.PARSE_FAILED")
+	}
+
+	return NewMemorySecrets(values), nil
+}
+
+// ResolveSecrets walks the config by its yaml tags and sets every field tagged `ccms:"key"` or `secret:"provider:key"`
+// from its provider, eg. `secret:"file:/etc/db/password"` or `secret:"env:DB_PASSWORD"`.
+// The secret takes precedence over the value in the config files.
+// Returns the resolved keys with the provider and key they were read from, the values are never returned
+func ResolveSecrets(conf *Config, providers SecretProviders) (map[string]string, error) {
+	resolved := map[string]string{}
+	err := resolveStruct(reflect.ValueOf(conf).Elem(), "", providers, resolved)
+	if err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// resolveStruct recursively resolves the secret fields of a struct
+func resolveStruct(v reflect.Value, path string, providers SecretProviders, resolved map[string]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlName(field)
+		if name == "" {
+			continue
+		}
+
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		fieldValue := v.Field(i)
+		if fieldValue.Kind() == reflect.Struct {
+			err := resolveStruct(fieldValue, fieldPath, providers, resolved)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		providerName, key, ok := secretTag(field)
+		if !ok {
+			continue
+		}
+		if key == "" {
+			return errors.NewBadRequest("Invalid secret tag for " + fieldPath + ", expected provider:key").SetCode("CONFIG.SECRET.INVALID_TAG")
+		}
+
+		provider, ok := providers[providerName]
+		if !ok {
+			return errors.NewBadRequest("Unknown secret provider " + providerName + " for " + fieldPath).SetCode("CONFIG.SECRET.UNKNOWN_PROVIDER")
+		}
+
+		value, err := provider.GetKey(key)
+		if err != nil {
+			newErr := errors.Get(err)
+			code := newErr.Code
+			if code == "" {
+				code = "CONFIG.SECRET.RESOLVE_FAILED"
+			}
+			return errors.New(errors.Error{
+				Kind:        newErr.Kind,
+				Code:        code,
+				Description: "Secret " + providerName + ":" + key + " for " + fieldPath + " could not be resolved : " + newErr.Description,
+			})
+		}
+
+		err = setValue(fieldValue, value)
+		if err != nil {
+			return errors.NewBadRequest("Invalid value in secret " + providerName + ":" + key + " for " + fieldPath + " : " + err.Error()).SetCode("CONFIG.SECRET.INVALID_VALUE")
+		}
+
+		resolved[fieldPath] = providerName + ":" + key
+	}
+
+	return nil
+}
+
+// secretTag returns the provider and the key of a secret field, false if the field is not a secret
+func secretTag(field reflect.StructField) (string, string, bool) {
+	if key, ok := field.Tag.Lookup(SecretCCMS); ok {
+		return SecretCCMS, key, true
+	}
+
+	tag, ok := field.Tag.Lookup("secret")
+	if !ok {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(tag, ":", 2)
+	if len(parts) != 2 {
+		return "", "", true
+	}
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ralstan-vaz/go-errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// secretConfig is used to test the secret tags as Config does not have secret fields yet
+type secretConfig struct {
+	Database struct {
+		Password string `yaml:"password" ccms:"db.password"`
+		User     string `yaml:"user" secret:"env:DB_USER"`
+	} `yaml:"database"`
+	Token   string        `yaml:"token" secret:"file:token"`
+	Timeout time.Duration `yaml:"timeout" secret:"ccms:db.timeout"`
+	Plain   string        `yaml:"plain"`
+}
+
+func resolveTestSecrets(conf interface{}, providers SecretProviders) (map[string]string, error) {
+	resolved := map[string]string{}
+	err := resolveStruct(reflect.ValueOf(conf).Elem(), "", providers, resolved)
+	return resolved, err
+}
+
+func TestResolveSecretsSuccess(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	err = ioutil.WriteFile(filepath.Join(dir, "token"), []byte("s3cr3t\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &secretConfig{Plain: "unchanged"}
+	conf.Database.Password = "from file"
+	resolved, err := resolveTestSecrets(conf, SecretProviders{
+		SecretCCMS: NewMemorySecrets(map[string]string{"db.password": "pass", "db.timeout": "5s"}),
+		SecretEnv:  &EnvSecrets{lookup: lookupFrom(map[string]string{"DB_USER": "admin"})},
+		SecretFile: FileSecrets{Dir: dir},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "pass", conf.Database.Password)
+	assert.Equal(t, "admin", conf.Database.User)
+	assert.Equal(t, "s3cr3t", conf.Token)
+	assert.Equal(t, 5*time.Second, conf.Timeout)
+	assert.Equal(t, "unchanged", conf.Plain)
+	assert.Equal(t, map[string]string{
+		"database.password": "ccms:db.password",
+		"database.user":     "env:DB_USER",
+		"token":             "file:token",
+		"timeout":           "ccms:db.timeout",
+	}, resolved)
+}
+
+func TestResolveSecretsNotFound(t *testing.T) {
+	conf := &secretConfig{}
+	_, err := resolveTestSecrets(conf, SecretProviders{
+		SecretCCMS: NewMemorySecrets(nil),
+		SecretEnv:  &EnvSecrets{lookup: lookupFrom(nil)},
+		SecretFile: FileSecrets{Dir: os.TempDir()},
+	})
+
+	assert.NotNil(t, err)
+	assert.True(t, errors.IsNotFound(err))
+	assert.Equal(t, "CONFIG.SECRET.NOT_FOUND", errors.Get(err).Code)
+}
+
+func TestResolveSecretsUnknownProvider(t *testing.T) {
+	conf := &secretConfig{}
+	_, err := resolveTestSecrets(conf, SecretProviders{})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "CONFIG.SECRET.UNKNOWN_PROVIDER", errors.Get(err).Code)
+}
+
+func TestMemorySecretsSet(t *testing.T) {
+	secrets := NewMemorySecrets(map[string]string{"key": "v1"})
+	secrets.Set("key", "v2")
+
+	value, err := secrets.GetKey(" key ")
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", value)
+}
+
+func TestLoadResolvesCCMSKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	file := filepath.Join(dir, "testing.yaml")
+	writeTestConfig(t, file, "ccms|ratings.url")
+
+	secrets := NewMemorySecrets(map[string]string{"ratings.url": "http://ratings/v1"})
+	ic := &IConfigModel{layers: []layer{{file: file}}, secrets: SecretProviders{SecretCCMS: secrets}, stop: make(chan struct{})}
+
+	model, _, err := ic.load()
+	assert.Nil(t, err)
+	assert.Equal(t, "http://ratings/v1", model.User.RatingsUrl)
+
+	// A changed key is picked up on reload
+	ic.model.Store(model)
+	secrets.Set("ratings.url", "http://ratings/v2")
+	ic.reload("ccms")
+	assert.Equal(t, "http://ratings/v2", ic.Get().User.RatingsUrl)
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// configValidator validates the config using the rules in the validate tags of the model.
+// Field names are reported using their yaml path eg. server.http.address
+var configValidator = newValidator()
+
+// ruleMessages describes the rules used in the config model
+var ruleMessages = map[string]string{
+	"required":      "is required",
+	"required_with": "is required along with",
+	"required_if":   "is required when",
+	"url":           "must be a valid URL",
+	"hostname_port": "must be in the host:port format",
+	"oneof":         "must be one of",
+	"min":           "must be at least",
+	"max":           "must be at most",
+	"gte":           "must be greater than or equal to",
+	"lte":           "must be less than or equal to",
+	"regexp":        "must be a valid regular expression",
+	"cidr|ip":       "must be an IP or a CIDR",
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return yamlName(field)
+	})
+	v.RegisterValidation("regexp", isRegexp)
+	return v
+}
+
+// isRegexp validates that the field is a valid regular expression
+func isRegexp(fl validator.FieldLevel) bool {
+	_, err := regexp.Compile(fl.Field().String())
+	return err == nil
+}
+
+// Validate checks the config against the rules declared in the validate tags of the model
+// eg. required, url, hostname_port (host:port), oneof (enum), min/max/gte/lte (range).
+// Every violation is listed in a single error along with the yaml path of the key
+func Validate(conf *Config) error {
+	err := configValidator.Struct(conf)
+	if err == nil {
+		return nil
+	}
+
+	fieldErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return errors.NewInternalError(err).SetCode("CONFIG.VALIDATION_FAILED")
+	}
+
+	violations := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		violations = append(violations, violation(fieldErr))
+	}
+
+	return errors.NewBadRequest("Invalid config : " + strings.Join(violations, "; ")).SetCode("CONFIG.VALIDATION_FAILED")
+}
+
+// violation describes a failed rule eg. "server.http.address must be in the host:port format (got :abc)"
+func violation(fieldErr validator.FieldError) string {
+	// Namespace is prefixed with the struct name eg. Config.server.http.address
+	path := fieldErr.Namespace()
+	if i := strings.Index(path, "."); i >= 0 {
+		path = path[i+1:]
+	}
+
+	message, ok := ruleMessages[fieldErr.Tag()]
+	if !ok {
+		message = "must satisfy " + fieldErr.Tag()
+	}
+	if fieldErr.Param() != "" {
+		message += " " + fieldErr.Param()
+	}
+
+	if !strings.HasPrefix(fieldErr.Tag(), "required") {
+		message += " (got " + valueString(fieldErr.Value()) + ")"
+	}
+
+	return path + " " + message
+}
+
+// valueString formats the rejected value for the violation
+func valueString(value interface{}) string {
+	s, ok := value.(string)
+	if ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
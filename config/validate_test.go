@@ -0,0 +1,78 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/ralstan-vaz/go-errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// validConfig returns a config that satisfies all the rules
+func validConfig() *Config {
+	conf := &Config{AppVersion: "1.0.0"}
+	conf.Server.HTTP.Address = ":80"
+	conf.Server.GRPC.Address = "localhost:5001"
+	conf.User.RatingsUrl = "http://ratings.local/v1"
+	conf.User.FavouritesUrl = ":5001"
+	return conf
+}
+
+func TestValidateSuccess(t *testing.T) {
+	err := Validate(validConfig())
+
+	assert.Nil(t, err)
+}
+
+func TestValidateAggregatesViolations(t *testing.T) {
+	conf := validConfig()
+	conf.Server.HTTP.Address = ":abc"
+	conf.User.FavouritesUrl = ""
+	conf.User.RatingsUrl = "ratings"
+
+	err := Validate(conf)
+
+	// All the violations are returned in a single error with their yaml path
+	assert.True(t, errors.IsBadRequest(err))
+	description := errors.Get(err).Description
+	assert.Contains(t, description, `server.http.address must be in the host:port format (got ":abc")`)
+	assert.Contains(t, description, "user.favouritesUrl is required")
+	assert.Contains(t, description, `user.ratingsUrl must be a valid URL (got "ratings")`)
+}
+
+func TestValidateRedactionPatterns(t *testing.T) {
+	conf := validConfig()
+	conf.Redaction.Patterns = []string{`(?i)bearer\s+\S+`, "("}
+
+	err := Validate(conf)
+
+	assert.True(t, errors.IsBadRequest(err))
+	assert.Contains(t, errors.Get(err).Description, `redaction.patterns[1] must be a valid regular expression (got "(")`)
+}
+
+func TestValidateTrustedProxies(t *testing.T) {
+	conf := validConfig()
+	conf.Server.HTTP.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1", "proxy"}
+
+	err := Validate(conf)
+
+	assert.True(t, errors.IsBadRequest(err))
+	assert.Contains(t, errors.Get(err).Description, `server.http.trustedProxies[2] must be an IP or a CIDR (got "proxy")`)
+}
+
+func TestValidateServerTLS(t *testing.T) {
+	conf := validConfig()
+	conf.Server.HTTP.TLS.Enabled = true
+	conf.Server.GRPC.TLS = ServerTLS{Enabled: true, CertFile: "server.crt", KeyFile: "server.key"}
+	// A client only presents a certificate to the servers requiring one
+	conf.User.RatingsTLS.Enabled = true
+
+	err := Validate(conf)
+
+	// An enabled server needs a certificate and a key
+	assert.True(t, errors.IsBadRequest(err))
+	description := errors.Get(err).Description
+	assert.Contains(t, description, "server.http.tls.certFile is required when Enabled true")
+	assert.Contains(t, description, "server.http.tls.keyFile is required when Enabled true")
+	assert.NotContains(t, description, "server.grpc.tls")
+	assert.NotContains(t, description, "user.ratingsTls")
+}
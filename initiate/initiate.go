@@ -7,8 +7,13 @@ import (
 	"go-boilerplate-api/pkg/clients/db"
 	grpcPkg "go-boilerplate-api/pkg/clients/grpc"
 	httpPkg "go-boilerplate-api/pkg/clients/http"
+	"go-boilerplate-api/pkg/health"
+	"go-boilerplate-api/pkg/metrics"
 	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/redact"
 	"go-boilerplate-api/shared"
+
+	"github.com/ralstan-vaz/go-errors"
 )
 
 // Initialize will initialize all the dependencies and the servers.
@@ -24,43 +29,71 @@ func Initialize() error {
 
 	log.Info("Enviroment: " + env)
 
+	// Gets config
+	conf, err := config.NewConfig(env, config.LocalFile)
+	if err != nil {
+		return err
+	}
+
+	// Applies the log levels of the config, again whenever it is reloaded
+	err = applyLogLevels(conf)
+	if err != nil {
+		return err
+	}
+
+	// Applies the redaction policy of the config, again whenever it is reloaded
+	err = applyRedaction(conf)
+	if err != nil {
+		return err
+	}
+
 	// Sets apm env
 	SetApmEnv(env)
 
-	// Initializes APM based on environment
-	if env != config.ENVDevelopment && env != config.ENVDocker && env != config.ENVSit {
-		// remove sit when building an actual app
+	// Initializes the APM agent only for the tiers that report to the apm server
+	if conf.Get().APM.Handler == apm.HandlerAgent {
 		apm.Initialize()
 	}
 
-	// Gets config
-	conf, err := config.NewConfig(env)
+	// Initializes apm Handler, falls back to the no-op handler if the agent is not initialized
+	handler := apm.NewApmHandler(conf)
+
+	// Initializes the metrics served on /metrics
+	appMetrics := metrics.NewMetrics()
+
+	// Initializes the DB connections
+	dbInstances, err := db.NewInstance(conf)
 	if err != nil {
 		return err
 	}
 
-	// Initializes the DB connections
-	dbInstances, err := db.NewInstance(conf)
+	// Initializes the GRPC connections, the outgoing calls are traced through the apm handler and recorded in the metrics
+	grpcCons, err := grpcPkg.NewConnections(conf, handler, appMetrics)
 	if err != nil {
 		return err
 	}
 
-	// Initializes the GRPC connections
-	grpcCons, err := grpcPkg.NewConnections(conf)
+	// Initializes the HTTP requester, the outgoing requests are traced through the apm handler and recorded in the metrics
+	httpRequester, err := httpPkg.NewRequest(conf, handler, appMetrics)
 	if err != nil {
 		return err
 	}
 
-	// Initializes apm Handler
-	handler := apm.NewApmHandler()
+	// Registers the dependencies the readiness of the app depends on
+	appHealth := health.NewHealth()
+	appHealth.Register("mydb", dbInstances)
+	appHealth.Register("grpc", grpcCons)
+	appHealth.Register("http", httpRequester)
 
 	// loads all common dependencies
 	dependencies := shared.Deps{
 		Config:        conf,
 		Database:      dbInstances,
 		GrpcConn:      grpcCons,
-		HTTPRequester: httpPkg.NewRequest(),
+		HTTPRequester: httpRequester,
 		Apm:           handler,
+		Health:        appHealth,
+		Metrics:       appMetrics,
 	}
 
 	// Initializes servers
@@ -72,3 +105,54 @@ func Initialize() error {
 	// Returns
 	return nil
 }
+
+// applyLogLevels sets the log levels of the config and subscribes to the config reloads to set them again
+func applyLogLevels(conf config.IConfig) error {
+	err := log.SetLevels(logLevels(conf.Get()))
+	if err != nil {
+		return err
+	}
+
+	conf.Subscribe(func(old, new *config.Config) {
+		err := log.SetLevels(logLevels(new))
+		if err != nil {
+			newErr := errors.Get(err)
+			log.Error(newErr.Code, "Log levels could not be applied : "+newErr.Description, log.Priority2, newErr.Source)
+		}
+	})
+	return nil
+}
+
+// logLevels returns the log levels of the config
+func logLevels(conf *config.Config) log.Levels {
+	return log.Levels{Level: conf.Log.Level, Packages: conf.Log.Packages}
+}
+
+// applyRedaction sets the redaction policy of the config and subscribes to the config reloads to set it again
+func applyRedaction(conf config.IConfig) error {
+	policy, err := redactionPolicy(conf.Get())
+	if err != nil {
+		return err
+	}
+	redact.SetPolicy(policy)
+
+	conf.Subscribe(func(old, new *config.Config) {
+		policy, err := redactionPolicy(new)
+		if err != nil {
+			newErr := errors.Get(err)
+			log.Error(newErr.Code, "Redaction policy could not be applied : "+newErr.Description, log.Priority2, newErr.Source)
+			return
+		}
+		redact.SetPolicy(policy)
+	})
+	return nil
+}
+
+// redactionPolicy creates the redaction policy of the config, the default fields are redacted if the config has none
+func redactionPolicy(conf *config.Config) (*redact.Policy, error) {
+	fields := conf.Redaction.Fields
+	if len(fields) == 0 {
+		fields = redact.DefaultFields
+	}
+	return redact.NewPolicy(fields, conf.Redaction.Patterns)
+}
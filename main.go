@@ -1,11 +1,9 @@
 package main
 
 import (
-	"go-boilerplate-api/initiate"
-	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/cmd"
 	"go-boilerplate-api/shared"
-
-	"github.com/ralstan-vaz/go-errors"
+	"os"
 )
 
 // Version ...
@@ -15,14 +13,6 @@ func main() {
 	// Sets the version flag
 	shared.VERSION = Version
 
-	// Initializes logger
-	log.InitLogger()
-
-	// Initialize the app
-	err := initiate.Initialize()
-	if err != nil {
-		newErr := errors.Get(err)
-		// If an error is encountered in main its critical and the app exits
-		log.Fatal(newErr.Code, newErr.Description, newErr.Source)
-	}
+	// Runs the subcommand, serve by default
+	os.Exit(cmd.Execute(os.Args[1:]))
 }
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"go-boilerplate-api/config"
 )
 
@@ -24,6 +25,19 @@ func NewInstance(conf config.IConfig) (*Instances, error) {
 	return dbInstances, nil
 }
 
+// HealthCheck checks if all the db instances are reachable
+func (i *Instances) HealthCheck(ctx context.Context) error {
+	return i.MyDB.Ping(ctx)
+}
+
+// Close closes the connections of all the db instances
+func (i *Instances) Close() error {
+	if i.MyDB == nil {
+		return nil
+	}
+	return i.MyDB.Close()
+}
+
 // Simulates the initialization of a db connection
 func initMyDB(config config.IConfig) (MyDBInterface, error) {
 	return NewMyDB(), nil
@@ -1,21 +1,118 @@
 package db
 
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go-boilerplate-api/pkg/utils"
+
+	goErrors "github.com/ralstan-vaz/go-errors"
+)
+
 // MyDBInterface ..
 type MyDBInterface interface {
-	GetOne(id string) MimicUser
-	Get(query string) []MimicUser
+	GetOne(id string) (MimicUser, error)
+	List(q Query) ([]MimicUser, int, error)
 	GetAll() []MimicUser
-	Insert(obj interface{}) error
+	Insert(obj interface{}) (string, error)
+	Update(id string, obj interface{}) error
+	Modify(id string, modify func(MimicUser) (MimicUser, error)) (MimicUser, error)
+	Delete(id string) error
+	Ping(ctx context.Context) error
+	Close() error
 }
 
 // NewMyDB ..
 func NewMyDB() MyDBInterface {
-	return &MyDB{connection: "connection established"}
+	return &MyDB{
+		connection: "connection established",
+		users: map[string]MimicUser{
+			"1": {ID: "1", Name: "Shepard"},
+			"2": {ID: "2", Name: "Miranda"},
+			"3": {ID: "3", Name: "Tali"},
+		},
+		lastID: 3,
+	}
 }
 
 // MyDB ..
 type MyDB struct {
 	connection string
+
+	// users mimics the user collection, kept in memory
+	mu    sync.RWMutex
+	users map[string]MimicUser
+	// lastID is the last ID assigned, it mimics an auto-incremented key
+	lastID int
+}
+
+// Filter operators
+const (
+	OpEq       string = "eq"
+	OpPrefix   string = "prefix"
+	OpContains string = "contains"
+)
+
+// Filter selects the users whose field matches the value with the operator, OpEq if there is none
+type Filter struct {
+	Field string `json:"field"`
+	Op    string `json:"op,omitempty"`
+	Value string `json:"value"`
+}
+
+// Sort orders the users by the field, in the descending order if Desc is set
+type Sort struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
+}
+
+// Query selects the users matching all the filters, in the order of the sorts and then of the IDs.
+// Only the users after the key After are returned, all of them if there is none, and at most Limit users, all of them if Limit is 0.
+// The fields and the operators are validated by the caller, an unknown one matches no user and does not sort them
+type Query struct {
+	Filters []Filter
+	Sort    []Sort
+	After   *Key
+	Limit   int
+}
+
+// Key is the position of a user in the order of the sorts, the values of its fields of the sorts and then its ID.
+// Paging with the key of the last user of a page neither skips nor repeats users when others are inserted or deleted in between
+type Key struct {
+	Values []string `json:"v,omitempty"`
+	ID     string   `json:"id"`
+}
+
+// KeyOf returns the key of the user in the order of the sorts
+func KeyOf(u MimicUser, sorts []Sort) Key {
+	k := Key{ID: u.ID}
+	for _, s := range sorts {
+		value, _ := field(u, s.Field)
+		k.Values = append(k.Values, value)
+	}
+	return k
+}
+
+// Less reports if the key is before the other key in the order of the sorts, both must be of the same sorts
+func (k Key) Less(other Key, sorts []Sort) bool {
+	for i, s := range sorts {
+		if i >= len(k.Values) || i >= len(other.Values) {
+			break
+		}
+		x, y := k.Values[i], other.Values[i]
+		if x == y {
+			continue
+		}
+		if s.Desc {
+			return x > y
+		}
+		return x < y
+	}
+	return k.ID < other.ID
 }
 
 // MimicUser Just to minic user collection
@@ -25,23 +122,169 @@ type MimicUser struct {
 }
 
 // GetOne ..
-func (m *MyDB) GetOne(id string) MimicUser {
-	return MimicUser{ID: id, Name: "Shepard"}
+func (m *MyDB) GetOne(id string) (MimicUser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return MimicUser{}, notFound(id)
+	}
+	return u, nil
 }
 
-// Get ..
-func (m *MyDB) Get(query string) []MimicUser {
+// List returns the page of the users matching the query along with the number of users matching its filters across all the pages
+func (m *MyDB) List(q Query) ([]MimicUser, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := []MimicUser{}
+	for _, u := range m.users {
+		if matches(u, q.Filters) {
+			users = append(users, u)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool { return KeyOf(users[i], q.Sort).Less(KeyOf(users[j], q.Sort), q.Sort) })
 
-	return []MimicUser{{ID: "1", Name: "Shepard"}, {ID: "2", Name: "Miranda"}}
+	total := len(users)
+	if q.After != nil {
+		users = users[sort.Search(total, func(i int) bool { return q.After.Less(KeyOf(users[i], q.Sort), q.Sort) }):]
+	}
+	if q.Limit > 0 && q.Limit < len(users) {
+		users = users[:q.Limit]
+	}
+	return users, total, nil
 }
 
 // GetAll ..
 func (m *MyDB) GetAll() []MimicUser {
-	return []MimicUser{{ID: "1", Name: "Shepard"},
-		{ID: "2", Name: "Miranda"}, {ID: "3", Name: "Tali"}}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]MimicUser, 0, len(m.users))
+	for _, u := range m.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
 }
 
-// Insert ..
-func (m *MyDB) Insert(obj interface{}) error {
+// Insert stores the object under a new ID and returns the ID, the ID of the object is ignored
+func (m *MyDB) Insert(obj interface{}) (string, error) {
+	u := MimicUser{}
+	err := utils.Bind(obj, &u)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID++
+	u.ID = strconv.Itoa(m.lastID)
+	m.users[u.ID] = u
+	return u.ID, nil
+}
+
+// Update replaces the user with the id, the id of the object is ignored
+func (m *MyDB) Update(id string, obj interface{}) error {
+	u := MimicUser{}
+	err := utils.Bind(obj, &u)
+	if err != nil {
+		return err
+	}
+	u.ID = id
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[id]; !ok {
+		return notFound(id)
+	}
+	m.users[id] = u
 	return nil
 }
+
+// Modify replaces the user with the id by the one modify returns from it and returns the stored user.
+// The user is read and replaced under the lock of the collection so that a concurrent write is not lost,
+// nothing is stored if modify fails. The id of the returned user is ignored
+func (m *MyDB) Modify(id string, modify func(MimicUser) (MimicUser, error)) (MimicUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.users[id]
+	if !ok {
+		return MimicUser{}, notFound(id)
+	}
+
+	u, err := modify(current)
+	if err != nil {
+		return MimicUser{}, err
+	}
+	u.ID = id
+	m.users[id] = u
+	return u, nil
+}
+
+// Delete ..
+func (m *MyDB) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[id]; !ok {
+		return notFound(id)
+	}
+	delete(m.users, id)
+	return nil
+}
+
+// Ping ..
+func (m *MyDB) Ping(ctx context.Context) error {
+	if m.connection != "connection established" {
+		return errors.New("mydb: " + m.connection)
+	}
+	return ctx.Err()
+}
+
+// Close ..
+func (m *MyDB) Close() error {
+	m.connection = "connection closed"
+	return nil
+}
+
+// matches reports if the user matches all the filters
+func matches(u MimicUser, filters []Filter) bool {
+	for _, f := range filters {
+		value, known := field(u, f.Field)
+		if !known {
+			return false
+		}
+
+		var ok bool
+		switch f.Op {
+		case OpEq, "":
+			ok = value == f.Value
+		case OpPrefix:
+			ok = strings.HasPrefix(value, f.Value)
+		case OpContains:
+			ok = strings.Contains(value, f.Value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// field returns the value of the field of the user, reporting if the field is known
+func field(u MimicUser, name string) (string, bool) {
+	switch name {
+	case "id":
+		return u.ID, true
+	case "name":
+		return u.Name, true
+	}
+	return "", false
+}
+
+// notFound is the error of a user missing from the collection
+func notFound(id string) error {
+	return goErrors.NewNotFound("User " + id + " does not exist").SetCode("PKG.CLIENTS.DB.USER_NOT_FOUND")
+}
@@ -1,9 +1,21 @@
 package grpc
 
 import (
+	"context"
+	"go-boilerplate-api/apis/middleware/apmgrpc"
+	"go-boilerplate-api/apis/middleware/metricsgrpc"
+	"go-boilerplate-api/apis/middleware/requestidgrpc"
+	"go-boilerplate-api/apm"
 	"go-boilerplate-api/config"
+	"go-boilerplate-api/pkg/metrics"
+	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/tlsconfig"
+	"sync"
+	"time"
 
+	"github.com/ralstan-vaz/go-errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 )
 
 // IGrpcConnections ...
@@ -11,28 +23,41 @@ type IGrpcConnections interface {
 	initialize() error
 	favouriteInit() error
 	GetFavourite() *grpc.ClientConn
+	HealthCheck(ctx context.Context) error
+	Close() error
 }
 
 // GrpcConnections contains all the GRPC connections this app uses
 type GrpcConnections struct {
+	// mu guards the connections which are replaced when their address or TLS config changes on config reload
+	mu                  sync.RWMutex
 	favouriteConnection *grpc.ClientConn
-	conf                config.IConfig
+	// favouriteTLS reloads the certificates of the favourite connection, nil when TLS is disabled
+	favouriteTLS *tlsconfig.Reloader
+	conf         config.IConfig
+	apm          apm.HandlerInterface
+	metrics      *metrics.Metrics
 }
 
-// NewConnections creates an instance of initialized GrpcConnections
-func NewConnections(conf config.IConfig) (IGrpcConnections, error) {
-	grpcCons := newGrpcConnections(conf)
+// NewConnections creates an instance of initialized GrpcConnections.
+// The calls made on the connections are traced through the apm handler and recorded in the metrics
+func NewConnections(conf config.IConfig, apmHandler apm.HandlerInterface, m *metrics.Metrics) (IGrpcConnections, error) {
+	grpcCons := newGrpcConnections(conf, apmHandler, m)
 	err := grpcCons.initialize()
 	if err != nil {
 		return nil, err
 	}
+
+	// Reconnects when the address or the TLS config of a connection changes
+	conf.Subscribe(grpcCons.onConfigReload)
+
 	return grpcCons, nil
 }
 
 // newGrpcConnections creates an instance of GrpcConnections
 // It does not initialize the connections.
-func newGrpcConnections(conf config.IConfig) *GrpcConnections {
-	return &GrpcConnections{conf: conf}
+func newGrpcConnections(conf config.IConfig, apmHandler apm.HandlerInterface, m *metrics.Metrics) *GrpcConnections {
+	return &GrpcConnections{conf: conf, apm: apmHandler, metrics: m}
 }
 
 // Initialize ..
@@ -46,15 +71,131 @@ func (g *GrpcConnections) initialize() error {
 
 // FavouriteInit ..
 func (g *GrpcConnections) favouriteInit() error {
-	favouriteCon, err := grpc.Dial(g.conf.Get().User.FavouritesUrl, grpc.WithInsecure())
+	conf := g.conf.Get()
+	reloader, err := newReloader(conf.User.FavouritesTLS, conf.Reload.Interval)
+	if err != nil {
+		return err
+	}
+
+	favouriteCon, err := grpc.Dial(conf.User.FavouritesUrl, g.dialOptions(reloader)...)
 	if err != nil {
+		if reloader != nil {
+			reloader.Close()
+		}
 		return err
 	}
-	g.favouriteConnection = favouriteCon
+
+	g.mu.Lock()
+	oldCon, oldTLS := g.favouriteConnection, g.favouriteTLS
+	g.favouriteConnection, g.favouriteTLS = favouriteCon, reloader
+	g.mu.Unlock()
+
+	// Closes the previous connection, if any, once it has been replaced
+	if oldCon != nil {
+		oldCon.Close()
+	}
+	if oldTLS != nil {
+		oldTLS.Close()
+	}
 	return nil
 }
 
+// newReloader loads the certificates of a connection, nil when TLS is disabled
+func newReloader(conf config.TLS, interval time.Duration) (*tlsconfig.Reloader, error) {
+	if !conf.Enabled {
+		return nil, nil
+	}
+	return tlsconfig.NewReloader(conf.Options(), interval)
+}
+
+// dialOptions returns the options the connections are dialed with, in plaintext unless there is a TLS reloader
+func (g *GrpcConnections) dialOptions(reloader *tlsconfig.Reloader) []grpc.DialOption {
+	transport := grpc.WithInsecure()
+	if reloader != nil {
+		transport = grpc.WithTransportCredentials(reloader.ClientCredentials())
+	}
+
+	return []grpc.DialOption{
+		transport,
+		grpc.WithChainUnaryInterceptor(
+			metricsgrpc.UnaryClientInterceptor(g.metrics),
+			requestidgrpc.UnaryClientInterceptor(),
+			apmgrpc.UnaryClientInterceptor(apmgrpc.WithAPM(g.apm)),
+		),
+	}
+}
+
+// onConfigReload re-dials the connections whose address or TLS config has changed
+func (g *GrpcConnections) onConfigReload(old, new *config.Config) {
+	if old.User.FavouritesUrl == new.User.FavouritesUrl && old.User.FavouritesTLS == new.User.FavouritesTLS {
+		return
+	}
+
+	err := g.favouriteInit()
+	if err != nil {
+		newErr := errors.NewInternalError(err).SetCode("PKG.CLIENTS.GRPC.RECONNECT_FAILED")
+		log.Error(newErr.Code, "Favourite connection could not be re-dialed : "+newErr.Description, log.Priority1, newErr.Source)
+		return
+	}
+
+	log.WithField("favouritesUrl", new.User.FavouritesUrl).Info("Favourite connection re-dialed")
+}
+
 // GetFavourite return the grpc connection for the favourite service
 func (g *GrpcConnections) GetFavourite() *grpc.ClientConn {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 	return g.favouriteConnection
 }
+
+// HealthCheck checks if all the grpc connections are ready.
+// Connections that are still connecting are given until the context expires to get ready
+func (g *GrpcConnections) HealthCheck(ctx context.Context) error {
+	return checkConnection(ctx, "favourite", g.GetFavourite())
+}
+
+// checkConnection waits for the connection to be ready or for the context to expire
+func checkConnection(ctx context.Context, name string, conn *grpc.ClientConn) error {
+	if conn == nil {
+		return notReady(name, "not initialized")
+	}
+
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return notReady(name, state.String())
+		}
+
+		// Blocks until the state changes or the context expires
+		if !conn.WaitForStateChange(ctx, state) {
+			return notReady(name, state.String())
+		}
+	}
+}
+
+// notReady creates the error returned when a connection is not ready
+func notReady(name string, state string) error {
+	return errors.New(errors.Error{
+		Kind:        errors.InternalError,
+		Code:        "PKG.CLIENTS.GRPC.CONNECTION_NOT_READY",
+		Description: name + " connection is not ready : " + state,
+	})
+}
+
+// Close closes all the grpc connections
+func (g *GrpcConnections) Close() error {
+	g.mu.RLock()
+	favouriteCon, favouriteTLS := g.favouriteConnection, g.favouriteTLS
+	g.mu.RUnlock()
+
+	if favouriteTLS != nil {
+		favouriteTLS.Close()
+	}
+	if favouriteCon == nil {
+		return nil
+	}
+	return favouriteCon.Close()
+}
@@ -1,22 +1,104 @@
 package http
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-boilerplate-api/apm"
+	"go-boilerplate-api/config"
+	"go-boilerplate-api/pkg/metrics"
+	log "go-boilerplate-api/pkg/utils/logger"
+	"go-boilerplate-api/pkg/utils/requestid"
+	"go-boilerplate-api/pkg/utils/tlsconfig"
+
+	"github.com/ralstan-vaz/go-errors"
 )
 
 // IRequest ...
 type IRequest interface {
-	New(URL string) (*InnerRequest, error)
+	New(ctx context.Context, URL string) (*InnerRequest, error)
 	Get(r *InnerRequest) (*http.Response, error)
+	HealthCheck(ctx context.Context) error
+	Close() error
 }
 
 // NewRequest Creates an instance if a request
-func NewRequest() IRequest {
-	return &Request{}
+// The requests are traced through the apm handler and recorded in the metrics.
+// The requests use the certificates of user.ratingsTls when it is enabled, its files are reloaded when they change
+// and the client is rebuilt when user.ratingsTls changes on config reload
+func NewRequest(conf config.IConfig, apmHandler apm.HandlerInterface, m *metrics.Metrics) (IRequest, error) {
+	r := &Request{conf: conf, apm: apmHandler, metrics: m}
+	err := r.clientInit()
+	if err != nil {
+		return nil, err
+	}
+
+	// Rebuilds the client when its TLS config changes
+	conf.Subscribe(r.onConfigReload)
+
+	return r, nil
 }
 
 // Request , is an invoker struct for the interface
 type Request struct {
+	conf    config.IConfig
+	apm     apm.HandlerInterface
+	metrics *metrics.Metrics
+	// mu guards the client which is replaced when the TLS config changes on config reload
+	mu     sync.RWMutex
+	client *http.Client
+	// reloader reloads the certificates of the client, nil when TLS is disabled
+	reloader *tlsconfig.Reloader
+}
+
+// clientInit builds the client from user.ratingsTls and replaces the current one, if any
+func (r *Request) clientInit() error {
+	conf := r.conf.Get()
+	client := &http.Client{}
+	var reloader *tlsconfig.Reloader
+	if conf.User.RatingsTLS.Enabled {
+		var err error
+		reloader, err = tlsconfig.NewReloader(conf.User.RatingsTLS.Options(), conf.Reload.Interval)
+		if err != nil {
+			return err
+		}
+		client = &http.Client{Transport: reloader.Transport()}
+	}
+
+	r.mu.Lock()
+	oldClient, oldTLS := r.client, r.reloader
+	r.client, r.reloader = client, reloader
+	r.mu.Unlock()
+
+	// The idle connections of the previous client are closed once it has been replaced, the in-flight requests complete
+	if oldClient != nil {
+		oldClient.CloseIdleConnections()
+	}
+	if oldTLS != nil {
+		oldTLS.Close()
+	}
+	return nil
+}
+
+// onConfigReload rebuilds the client when its TLS config has changed
+func (r *Request) onConfigReload(old, new *config.Config) {
+	if old.User.RatingsTLS == new.User.RatingsTLS {
+		return
+	}
+
+	err := r.clientInit()
+	if err != nil {
+		newErr := errors.NewInternalError(err).SetCode("PKG.CLIENTS.HTTP.RELOAD_FAILED")
+		log.Error(newErr.Code, "Ratings client could not be rebuilt, the current one is kept : "+newErr.Description, log.Priority1, newErr.Source)
+		return
+	}
+
+	log.WithField("ratingsTlsEnabled", new.User.RatingsTLS.Enabled).Info("Ratings client rebuilt")
 }
 
 // InnerRequest contains a method to perform an HTTP request
@@ -24,29 +106,115 @@ type InnerRequest struct {
 	Req *http.Request
 }
 
-// New ...
-func (r *Request) New(URL string) (*InnerRequest, error) {
+// New creates a request bound to the context, the apm transaction of the context is the parent of the request segment.
+// The request ID of the context, if any, is forwarded in the X-Request-ID header
+func (r *Request) New(ctx context.Context, URL string) (*InnerRequest, error) {
 	var err error
 	newIReq := InnerRequest{Req: nil}
-	newIReq.Req, err = http.NewRequest("", URL, nil)
+	newIReq.Req, err = http.NewRequestWithContext(ctx, "", URL, nil)
 	if err != nil {
 		return nil, err
 	}
+	if id := requestid.FromContext(ctx); id != "" {
+		newIReq.Req.Header.Set(requestid.Header, id)
+	}
 	return &newIReq, nil
 }
 
 // Get makes an http get request
-// Interceptors can be added here for tracing etc
+// The request is traced as an external segment, its trace context is sent in the traceparent header
 func (r *Request) Get(req *InnerRequest) (*http.Response, error) {
 	req.Req.Method = "GET"
-	return do(req)
+	return r.observe(req, func(req *InnerRequest) (*http.Response, error) {
+		return r.trace(req, r.do)
+	})
 }
 
-func do(r *InnerRequest) (*http.Response, error) {
-	client := &http.Client{}
-	resp, err := client.Do(r.Req)
+// HealthCheck checks if the hosts of the http APIs this app depends on are reachable.
+// Only a TCP connection is made so that the APIs themselves are not invoked
+func (r *Request) HealthCheck(ctx context.Context) error {
+	return dial(ctx, r.conf.Get().User.RatingsUrl)
+}
+
+// observe records the status and the latency of the call in the metrics, labelled with the host of the request
+func (r *Request) observe(req *InnerRequest, call func(*InnerRequest) (*http.Response, error)) (*http.Response, error) {
+	if r.metrics == nil {
+		return call(req)
+	}
+
+	start := time.Now()
+	resp, err := call(req)
+
+	code := "error"
+	failed := true
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+		failed = resp.StatusCode >= http.StatusInternalServerError
+	}
+	r.metrics.ObserveClient(metrics.ClientHTTP, req.Req.URL.Host, code, failed, time.Since(start))
+
+	return resp, err
+}
+
+// trace wraps the call in an external segment of the apm transaction of the request context
+func (r *Request) trace(req *InnerRequest, call func(*InnerRequest) (*http.Response, error)) (*http.Response, error) {
+	if r.apm == nil {
+		return call(req)
+	}
+
+	segment, err := r.apm.StartExternalWebSegment(req.Req.Context(), req.Req)
+	if err != nil {
+		log.FromContext(req.Req.Context()).Error("PKG.CLIENTS.HTTP.APM_SEGMENT_INIT_FAIL", "Segment failed", log.Priority1, nil, map[string]interface{}{"error": err.Error()})
+	}
+	defer r.apm.EndExternalSegment(segment)
+
+	return call(req)
+}
+
+func (r *Request) do(req *InnerRequest) (*http.Response, error) {
+	r.mu.RLock()
+	client := r.client
+	r.mu.RUnlock()
+
+	resp, err := client.Do(req.Req)
 	if err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
+
+// Close stops reloading the certificates of the client, if any
+func (r *Request) Close() error {
+	r.mu.RLock()
+	reloader := r.reloader
+	r.mu.RUnlock()
+
+	if reloader == nil {
+		return nil
+	}
+	return reloader.Close()
+}
+
+// dial opens and closes a TCP connection to the host of the URL
+func dial(ctx context.Context, URL string) error {
+	u, err := url.Parse(URL)
+	if err != nil {
+		return errors.NewInternalError(err).SetCode("PKG.CLIENTS.HTTP.INVALID_URL")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return errors.NewInternalError(err).SetCode("PKG.CLIENTS.HTTP.HOST_UNREACHABLE")
+	}
+
+	return conn.Close()
+}
@@ -0,0 +1,128 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// StatusUp is reported for a dependency whose check passed
+	StatusUp string = "up"
+	// StatusDown is reported for a dependency whose check failed
+	StatusDown string = "down"
+	// StatusReady is reported when all the dependencies are up
+	StatusReady string = "ready"
+	// StatusNotReady is reported when a dependency is down or the app is draining
+	StatusNotReady string = "not ready"
+
+	// defaultCheckTimeout is the time each dependency check gets to complete
+	defaultCheckTimeout = 2 * time.Second
+)
+
+// Checker is implemented by any dependency whose health can be checked
+// eg. db.Instances, grpc.GrpcConnections and http.Request
+type Checker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Health keeps track of the dependencies that the readiness of the app depends on
+type Health struct {
+	checks   []check
+	draining int32
+	timeout  time.Duration
+}
+
+// check is a named dependency check
+type check struct {
+	name    string
+	checker Checker
+}
+
+// Report is the result of a readiness check
+type Report struct {
+	Ready    bool                   `json:"-"`
+	Status   string                 `json:"status"`
+	Draining bool                   `json:"draining,omitempty"`
+	Checks   map[string]CheckResult `json:"checks"`
+}
+
+// CheckResult is the result of checking a single dependency
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// NewHealth creates an instance of Health without any dependencies
+func NewHealth() *Health {
+	return &Health{timeout: defaultCheckTimeout}
+}
+
+// Register adds a dependency to the readiness checks
+func (h *Health) Register(name string, checker Checker) {
+	h.checks = append(h.checks, check{name: name, checker: checker})
+}
+
+// Drain marks the app as draining, after which it is reported as not ready
+func (h *Health) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+// IsDraining reports if the app is draining
+func (h *Health) IsDraining() bool {
+	return atomic.LoadInt32(&h.draining) == 1
+}
+
+// Ready checks all the registered dependencies concurrently and reports their status and latency
+func (h *Health) Ready(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(h.checks))
+
+	var wg sync.WaitGroup
+	wg.Add(len(h.checks))
+	for i := range h.checks {
+		go func(i int) {
+			defer wg.Done()
+			results[i] = run(ctx, h.checks[i].checker)
+		}(i)
+	}
+	wg.Wait()
+
+	report := Report{Ready: true, Status: StatusReady, Checks: map[string]CheckResult{}}
+	for i := range h.checks {
+		report.Checks[h.checks[i].name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Ready = false
+			report.Status = StatusNotReady
+		}
+	}
+
+	if h.IsDraining() {
+		report.Ready = false
+		report.Status = StatusNotReady
+		report.Draining = true
+	}
+
+	return report
+}
+
+// run checks a single dependency and measures the time it took
+func run(ctx context.Context, checker Checker) CheckResult {
+	start := time.Now()
+	err := checker.HealthCheck(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Create a MockChecker struct with an embedded mock instance
+type MockChecker struct {
+	mock.Mock
+}
+
+// MOCKS -----------------
+func (m *MockChecker) HealthCheck(ctx context.Context) error {
+	// This allows us to pass in mocked results, so that the mock will return whatever we define
+	returnVals := m.Called()
+	// return the values which we define
+	return returnVals.Error(0)
+}
+
+//////
+
+// TESTS ---------------------
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+func TestReadySuccess(t *testing.T) {
+	up := new(MockChecker)
+	up.On("HealthCheck").Return(nil)
+
+	h := NewHealth()
+	h.Register("db", up)
+
+	report := h.Ready(context.Background())
+
+	up.AssertExpectations(t)
+	assert.True(t, report.Ready)
+	assert.Equal(t, StatusReady, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["db"].Status)
+}
+
+func TestReadyDependencyDown(t *testing.T) {
+	up := new(MockChecker)
+	up.On("HealthCheck").Return(nil)
+	down := new(MockChecker)
+	down.On("HealthCheck").Return(errors.New("connection refused"))
+
+	h := NewHealth()
+	h.Register("db", up)
+	h.Register("grpc", down)
+
+	report := h.Ready(context.Background())
+
+	assert.False(t, report.Ready)
+	assert.Equal(t, StatusNotReady, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["db"].Status)
+	assert.Equal(t, StatusDown, report.Checks["grpc"].Status)
+	assert.Equal(t, "connection refused", report.Checks["grpc"].Error)
+}
+
+func TestReadyDraining(t *testing.T) {
+	up := new(MockChecker)
+	up.On("HealthCheck").Return(nil)
+
+	h := NewHealth()
+	h.Register("db", up)
+	h.Drain()
+
+	report := h.Ready(context.Background())
+
+	assert.False(t, report.Ready)
+	assert.True(t, report.Draining)
+	assert.Equal(t, StatusNotReady, report.Status)
+}
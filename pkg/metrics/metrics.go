@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// UnmatchedRoute is the route label of the http requests that did not match any route,
+	// the raw paths are never used as labels so that the number of series stays bounded
+	UnmatchedRoute string = "unmatched"
+
+	// ClientHTTP is the client label of the outbound http calls
+	ClientHTTP string = "http"
+	// ClientGRPC is the client label of the outbound grpc calls
+	ClientGRPC string = "grpc"
+)
+
+// Metrics keeps the rate, errors and duration (RED) metrics of the requests served and the calls made by the app
+type Metrics struct {
+	registry *Registry
+
+	httpRequests *CounterVec
+	httpErrors   *CounterVec
+	httpDuration *HistogramVec
+
+	grpcRequests *CounterVec
+	grpcErrors   *CounterVec
+	grpcDuration *HistogramVec
+
+	clientRequests *CounterVec
+	clientErrors   *CounterVec
+	clientDuration *HistogramVec
+}
+
+// NewMetrics creates an instance of Metrics along with the go runtime stats
+func NewMetrics() *Metrics {
+	r := NewRegistry()
+	m := &Metrics{
+		registry: r,
+
+		httpRequests: r.NewCounterVec("http_server_requests_total", "Number of http requests served, by route template and status code.", "method", "route", "code"),
+		httpErrors:   r.NewCounterVec("http_server_request_errors_total", "Number of http requests that failed with a 5xx status code.", "method", "route"),
+		httpDuration: r.NewHistogramVec("http_server_request_duration_seconds", "Latency of the http requests served.", DefaultBuckets, "method", "route"),
+
+		grpcRequests: r.NewCounterVec("grpc_server_requests_total", "Number of grpc requests served, by full method and status code.", "method", "code"),
+		grpcErrors:   r.NewCounterVec("grpc_server_request_errors_total", "Number of grpc requests that failed with a server error code.", "method"),
+		grpcDuration: r.NewHistogramVec("grpc_server_request_duration_seconds", "Latency of the grpc requests served.", DefaultBuckets, "method"),
+
+		clientRequests: r.NewCounterVec("client_requests_total", "Number of outbound calls, by client, target and status code.", "client", "target", "code"),
+		clientErrors:   r.NewCounterVec("client_request_errors_total", "Number of outbound calls that failed.", "client", "target"),
+		clientDuration: r.NewHistogramVec("client_request_duration_seconds", "Latency of the outbound calls.", DefaultBuckets, "client", "target"),
+	}
+	r.Register(NewRuntimeCollector())
+
+	return m
+}
+
+// Registry returns the registry of the metrics, more metrics can be registered on it
+func (m *Metrics) Registry() *Registry {
+	return m.registry
+}
+
+// Handler serves the metrics in the Prometheus text format, nil metrics are not found
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return m.registry.Handler()
+}
+
+// ObserveHTTP records an http request served.
+// The route is the route template eg. /users/:userId, empty if the request did not match any route.
+// The Observe methods do nothing on nil metrics, so that the middlewares work with the metrics off
+func (m *Metrics) ObserveHTTP(method string, route string, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	if route == "" {
+		route = UnmatchedRoute
+	}
+
+	m.httpRequests.Inc(method, route, strconv.Itoa(status))
+	if status >= http.StatusInternalServerError {
+		m.httpErrors.Inc(method, route)
+	}
+	m.httpDuration.Observe(elapsed.Seconds(), method, route)
+}
+
+// ObserveGRPC records a grpc request served, method is the full method eg. /proto.UserService/GetAll
+func (m *Metrics) ObserveGRPC(method string, code codes.Code, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.grpcRequests.Inc(method, code.String())
+	if isServerError(code) {
+		m.grpcErrors.Inc(method)
+	}
+	m.grpcDuration.Observe(elapsed.Seconds(), method)
+}
+
+// ObserveClient records an outbound call.
+// The target identifies the callee without unbounded parts eg. the host of an http API or the full grpc method
+func (m *Metrics) ObserveClient(client string, target string, code string, failed bool, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.clientRequests.Inc(client, target, code)
+	if failed {
+		m.clientErrors.Inc(client, target)
+	}
+	m.clientDuration.Observe(elapsed.Seconds(), client, target)
+}
+
+// isServerError reports if the grpc code is caused by the server rather than by the request,
+// like the 5xx status codes in http
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
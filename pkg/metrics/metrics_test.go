@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+// text returns the metrics of the registry in the Prometheus text format
+func text(r *Registry) string {
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	return buf.String()
+}
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("requests_total", "Number of requests.", "route")
+	c.Inc("/users/:userId")
+	c.Add(2, "/users/:userId")
+	c.Inc(`/quote"d`)
+	c.Add(-1, "/ping/")
+
+	assert.Equal(t, `# HELP requests_total Number of requests.
+# TYPE requests_total counter
+requests_total{route="/quote\"d"} 1
+requests_total{route="/users/:userId"} 3
+`, text(r))
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("latency_seconds", "Latency.", []float64{0.1, 1}, "method")
+	h.Observe(0.05, "GET")
+	h.Observe(0.1, "GET")
+	h.Observe(0.5, "GET")
+	h.Observe(3, "GET")
+
+	assert.Equal(t, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{method="GET",le="0.1"} 2
+latency_seconds_bucket{method="GET",le="1"} 3
+latency_seconds_bucket{method="GET",le="+Inf"} 4
+latency_seconds_sum{method="GET"} 3.65
+latency_seconds_count{method="GET"} 4
+`, text(r))
+}
+
+func TestRegistryPanics(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("requests_total", "Number of requests.", "route")
+
+	assert.Panics(t, func() { r.NewCounterVec("requests_total", "Duplicate.") })
+	assert.Panics(t, func() { c.Inc("GET", "/ping/") })
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	m.ObserveHTTP(http.MethodGet, "/users/:userId", http.StatusOK, 20*time.Millisecond)
+	m.ObserveHTTP(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+	m.ObserveHTTP(http.MethodPost, "/users/", http.StatusInternalServerError, time.Millisecond)
+	m.ObserveGRPC("/proto.UserService/GetAll", codes.NotFound, time.Millisecond)
+	m.ObserveGRPC("/proto.UserService/GetAll", codes.Internal, time.Millisecond)
+	m.ObserveClient(ClientHTTP, "www.mocky.io", "error", true, time.Second)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `http_server_requests_total{method="GET",route="/users/:userId",code="200"} 1`)
+	assert.Contains(t, body, `http_server_requests_total{method="GET",route="unmatched",code="404"} 1`)
+	assert.Contains(t, body, `http_server_request_errors_total{method="POST",route="/users/"} 1`)
+	assert.NotContains(t, body, `http_server_request_errors_total{method="GET"`)
+	assert.Contains(t, body, `grpc_server_requests_total{method="/proto.UserService/GetAll",code="NotFound"} 1`)
+	assert.Contains(t, body, `grpc_server_request_errors_total{method="/proto.UserService/GetAll"} 1`)
+	assert.Contains(t, body, `client_request_errors_total{client="http",target="www.mocky.io"} 1`)
+	assert.Contains(t, body, `client_request_duration_seconds_bucket{client="http",target="www.mocky.io",le="1"} 1`)
+	assert.Contains(t, body, "# TYPE go_goroutines gauge")
+	assert.True(t, strings.HasSuffix(body, "\n"))
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+
+	// Nothing is recorded when the metrics are off
+	assert.NotPanics(t, func() {
+		m.ObserveHTTP(http.MethodGet, "/users/:userId", http.StatusOK, time.Millisecond)
+		m.ObserveGRPC("/proto.UserService/GetAll", codes.OK, time.Millisecond)
+		m.ObserveClient(ClientHTTP, "www.mocky.io", "200", false, time.Millisecond)
+	})
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,324 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// ContentType is the content type of the Prometheus text exposition format
+	ContentType string = "text/plain; version=0.0.4; charset=utf-8"
+
+	// labelSeparator joins the label values into the key of a series, it cannot appear in valid utf-8
+	labelSeparator = "\xff"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the latency histograms
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// collector writes its metrics in the Prometheus text format
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry keeps the metrics of the app and writes them in the Prometheus text format.
+// It only supports what the app needs: counters, histograms and gauges read on scrape
+type Registry struct {
+	mu         sync.Mutex
+	names      map[string]bool
+	collectors []collector
+}
+
+// NewRegistry creates an empty instance of Registry
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+// register adds the collector of the metric names to the registry.
+// A name registered twice is a programming error, so it panics
+func (r *Registry) register(c collector, names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range names {
+		if r.names[name] {
+			panic("metrics: " + name + " is already registered")
+		}
+		r.names[name] = true
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+// NewCounterVec creates and registers a counter partitioned by the labels
+func (r *Registry) NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name: name, help: help, labels: labels}, series: map[string]*counter{}}
+	r.register(c, name)
+	return c
+}
+
+// NewHistogramVec creates and registers a histogram partitioned by the labels.
+// The buckets are the sorted upper bounds of the histogram, +Inf is added implicitly
+func (r *Registry) NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{desc: desc{name: name, help: help, labels: labels}, buckets: buckets, series: map[string]*histogram{}}
+	r.register(h, name)
+	return h
+}
+
+// Register adds a collector that reads its values on every scrape eg. the go runtime stats
+func (r *Registry) Register(c Collector) {
+	r.register(collectorFunc(c.Collect), c.Names()...)
+}
+
+// WriteText writes all the metrics in the Prometheus text format, sorted by name within each collector
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buf)
+	}
+	return buf.Flush()
+}
+
+// Handler serves the metrics in the Prometheus text format eg. on /metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.WriteText(w)
+	})
+}
+
+// Collector is implemented by the metrics whose values are read on every scrape
+type Collector interface {
+	// Names returns the names of the metrics written by Collect
+	Names() []string
+	// Collect writes the current values through the writer
+	Collect(w *Writer)
+}
+
+// collectorFunc adapts the Collect method of a Collector to a collector
+type collectorFunc func(w *Writer)
+
+func (f collectorFunc) write(w *bufio.Writer) {
+	f(&Writer{w: w})
+}
+
+// Writer writes the samples of a Collector in the Prometheus text format
+type Writer struct {
+	w *bufio.Writer
+}
+
+// Gauge writes a gauge sample
+func (w *Writer) Gauge(name string, help string, value float64, labels ...Label) {
+	writeHeader(w.w, name, help, "gauge")
+	writeSample(w.w, name, labels, value)
+}
+
+// Counter writes a counter sample
+func (w *Writer) Counter(name string, help string, value float64, labels ...Label) {
+	writeHeader(w.w, name, help, "counter")
+	writeSample(w.w, name, labels, value)
+}
+
+// Label is the name and the value of a label of a sample
+type Label struct {
+	Name  string
+	Value string
+}
+
+// desc describes a metric partitioned by labels
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+// key joins the label values into the key of a series, the number of values must match the labels
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic("metrics: " + d.name + " expects " + strconv.Itoa(len(d.labels)) + " label values, got " + strconv.Itoa(len(values)))
+	}
+	return strings.Join(values, labelSeparator)
+}
+
+// pairs pairs the label names with the values of a series
+func (d desc) pairs(values []string) []Label {
+	labels := make([]Label, len(values))
+	for i, value := range values {
+		labels[i] = Label{Name: d.labels[i], Value: value}
+	}
+	return labels
+}
+
+// CounterVec is a counter partitioned by labels eg. the number of requests per route and status
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*counter
+}
+
+// counter is a series of a CounterVec
+type counter struct {
+	values []string
+	value  float64
+}
+
+// Inc increments the counter of the label values by 1
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds the delta to the counter of the label values, a negative delta is ignored since counters only go up
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if delta < 0 {
+		return
+	}
+	key := c.key(values)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.series[key]
+	if !ok {
+		s = &counter{values: append([]string(nil), values...)}
+		c.series[key] = s
+	}
+	s.value += delta
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		writeSample(w, c.name, c.pairs(s.values), s.value)
+	}
+}
+
+// HistogramVec is a histogram partitioned by labels eg. the latency of the requests per route
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogram
+}
+
+// histogram is a series of a HistogramVec, the counts are per bucket and are accumulated when written
+type histogram struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe adds the value to the histogram of the label values
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	key := h.key(values)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{values: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+
+	// The value falls in the first bucket whose upper bound is greater than or equal to it, if any
+	i := sort.SearchFloat64s(h.buckets, value)
+	if i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		labels := h.pairs(s.values)
+
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			writeSample(w, h.name+"_bucket", append(labels, Label{Name: "le", Value: formatFloat(bound)}), float64(cumulative))
+		}
+		writeSample(w, h.name+"_bucket", append(labels, Label{Name: "le", Value: "+Inf"}), float64(s.count))
+		writeSample(w, h.name+"_sum", labels, s.sum)
+		writeSample(w, h.name+"_count", labels, float64(s.count))
+	}
+}
+
+// sortedKeys returns the keys of the series sorted so that the output is stable between scrapes
+func sortedKeys(series interface{}) []string {
+	var keys []string
+	switch m := series.(type) {
+	case map[string]*counter:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[string]*histogram:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric
+func writeHeader(w *bufio.Writer, name string, help string, kind string) {
+	w.WriteString("# HELP " + name + " " + helpEscaper.Replace(help) + "\n")
+	w.WriteString("# TYPE " + name + " " + kind + "\n")
+}
+
+// writeSample writes a sample line eg. http_server_requests_total{method="GET",route="/ping/"} 3
+func writeSample(w *bufio.Writer, name string, labels []Label, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(label.Name + `="` + labelEscaper.Replace(label.Value) + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+// formatFloat formats the value the way Prometheus parses it
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	// helpEscaper escapes the backslashes and the new lines of the help text
+	helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	// labelEscaper escapes the backslashes, the double quotes and the new lines of the label values
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
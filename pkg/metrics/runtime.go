@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"runtime"
+)
+
+// RuntimeCollector collects the go runtime stats.
+// The memory stats are read once per scrape since reading them briefly stops the world
+type RuntimeCollector struct {
+}
+
+// NewRuntimeCollector creates an instance of RuntimeCollector
+func NewRuntimeCollector() *RuntimeCollector {
+	return &RuntimeCollector{}
+}
+
+// Names returns the names of the runtime metrics
+func (c *RuntimeCollector) Names() []string {
+	return []string{
+		"go_info",
+		"go_goroutines",
+		"go_threads",
+		"go_memstats_alloc_bytes",
+		"go_memstats_sys_bytes",
+		"go_memstats_heap_inuse_bytes",
+		"go_memstats_heap_objects",
+		"go_memstats_mallocs_total",
+		"go_memstats_frees_total",
+		"go_gc_cycles_total",
+		"go_gc_pause_seconds_total",
+		"go_memstats_last_gc_time_seconds",
+	}
+}
+
+// Collect writes the current runtime stats
+func (c *RuntimeCollector) Collect(w *Writer) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	threads, _ := runtime.ThreadCreateProfile(nil)
+
+	w.Gauge("go_info", "Information about the Go environment.", 1, Label{Name: "version", Value: runtime.Version()})
+	w.Gauge("go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine()))
+	w.Gauge("go_threads", "Number of OS threads created.", float64(threads))
+	w.Gauge("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", float64(stats.Alloc))
+	w.Gauge("go_memstats_sys_bytes", "Number of bytes obtained from the system.", float64(stats.Sys))
+	w.Gauge("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", float64(stats.HeapInuse))
+	w.Gauge("go_memstats_heap_objects", "Number of allocated objects.", float64(stats.HeapObjects))
+	w.Counter("go_memstats_mallocs_total", "Total number of mallocs.", float64(stats.Mallocs))
+	w.Counter("go_memstats_frees_total", "Total number of frees.", float64(stats.Frees))
+	w.Counter("go_gc_cycles_total", "Number of completed GC cycles.", float64(stats.NumGC))
+	w.Counter("go_gc_pause_seconds_total", "Total time spent in GC stop-the-world pauses.", float64(stats.PauseTotalNs)/1e9)
+	w.Gauge("go_memstats_last_gc_time_seconds", "Number of seconds since 1970 of the last garbage collection.", float64(stats.LastGC)/1e9)
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	user "go-boilerplate-api/apis/grpc/generated/user"
 	"go-boilerplate-api/config"
+	"go-boilerplate-api/pkg/utils/errdetails/grpcstatus"
 	log "go-boilerplate-api/pkg/utils/logger"
 )
 
 // FavouriteInterface is implemented by any value that contains the required methods
 // makes Favourite mockable
 type FavouriteInterface interface {
-	Get(GetRequest) (*GetResponse, error)
+	Get(context.Context, GetRequest) (*GetResponse, error)
 }
 
 // NewFavourite create an instance of favourite
@@ -26,21 +27,26 @@ type Favourite struct {
 }
 
 // Get Make the request to favourites
-func (f *Favourite) Get(req GetRequest) (*GetResponse, error) {
-	resp, err := f.client.GetFav(f.grpcCon)
+func (f *Favourite) Get(ctx context.Context, req GetRequest) (*GetResponse, error) {
+	resp, err := f.client.GetFav(ctx, f.grpcCon)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Info("Response : ", resp)
+	log.FromContext(ctx).Info("Response : ", resp)
 	res := GetResponse{}
 	res.Beers = []string{"Moon Shine", "Bira", "Simba"}
 	return &res, nil
 }
 
 // The reason for this is to avoid calling the actual grpc functions during testing , need to find a better way around
-func (c *gclient) GetFav(grpcCon grpcConnectioner) (*user.Users, error) {
+// The context carries the apm transaction the call is traced in, the status of a failed call is converted back to an error along with its details
+func (c *gclient) GetFav(ctx context.Context, grpcCon grpcConnectioner) (*user.Users, error) {
 	favGrpcCon := grpcCon.GetFavourite()
 	cli := user.NewUserServiceClient(favGrpcCon)
-	return cli.GetAll(context.Background(), &user.UserGetRequest{})
+	res, err := cli.GetAll(ctx, &user.UserGetRequest{})
+	if err != nil {
+		return nil, grpcstatus.FromError(err)
+	}
+	return res, nil
 }
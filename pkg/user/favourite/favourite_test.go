@@ -1,6 +1,7 @@
 package favourite
 
 import (
+	"context"
 	user "go-boilerplate-api/apis/grpc/generated/user"
 	"os"
 	"testing"
@@ -15,7 +16,7 @@ type MockStore struct {
 }
 
 // MOCKS -----------------
-func (m *MockStore) GetFav(grpcCon grpcConnectioner) (*user.Users, error) {
+func (m *MockStore) GetFav(ctx context.Context, grpcCon grpcConnectioner) (*user.Users, error) {
 	// This allows us to pass in mocked results, so that the mock store will return whatever we define
 	returnVals := m.Called(grpcCon)
 	// return the values which we define
@@ -58,7 +59,7 @@ func TestModuleSuccess(t *testing.T) {
 	s := Favourite{nil, nil, m}
 
 	// Calls the actual module function
-	resp, err := s.Get(req)
+	resp, err := s.Get(context.Background(), req)
 
 	// The expectations that we defined for our mock store earlier are asserted here
 	m.AssertExpectations(t)
@@ -89,7 +90,7 @@ func TestModuleFail(t *testing.T) {
 	s := Favourite{nil, nil, m}
 
 	// Calls the actual module function
-	resp, err := s.Get(req)
+	resp, err := s.Get(context.Background(), req)
 
 	// The expectations that we defined for our mock store earlier are asserted here
 	m.AssertExpectations(t)
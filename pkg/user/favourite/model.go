@@ -1,6 +1,7 @@
 package favourite
 
 import (
+	"context"
 	user "go-boilerplate-api/apis/grpc/generated/user"
 
 	"google.golang.org/grpc"
@@ -14,7 +15,7 @@ type grpcConnectioner interface {
 
 // Used to provide interface for calling proto client funcs
 type grpcClient interface {
-	GetFav(grpcCon grpcConnectioner) (*user.Users, error)
+	GetFav(ctx context.Context, grpcCon grpcConnectioner) (*user.Users, error)
 }
 
 // dummy struct used for interfacing
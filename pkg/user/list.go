@@ -0,0 +1,130 @@
+package user
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"go-boilerplate-api/pkg/clients/db"
+	"go-boilerplate-api/pkg/user/repo"
+	"go-boilerplate-api/pkg/utils/errdetails"
+)
+
+// Limits of the number of users of a page
+const (
+	DefaultLimit int = 20
+	MaxLimit     int = 100
+)
+
+// listFields are the fields the users can be filtered and sorted by, the repo trusts the queries validated here
+var listFields = map[string]bool{"id": true, "name": true}
+
+// listOps are the filter operators
+var listOps = map[string]bool{"": true, db.OpEq: true, db.OpPrefix: true, db.OpContains: true}
+
+// cursor is the position of a page in the users matching a query, encoded in the opaque NextCursor.
+// It is the key of the last user of the previous page so that the next page starts right after it,
+// even if users were inserted or deleted in between
+type cursor struct {
+	After repo.Key `json:"a"`
+	// Query is the hash of the filters and the sorts of the query the cursor was created for
+	Query string `json:"q"`
+}
+
+// validate checks the fields, the operators and the limit of the query
+func (q ListQuery) validate() error {
+	for i, f := range q.Filters {
+		field := "filters[" + strconv.Itoa(i) + "]"
+		if !listFields[f.Field] {
+			return errdetails.InvalidField("PKG.USER.INVALID_FILTER_FIELD", field+".field", "The users can not be filtered by "+f.Field)
+		}
+		if !listOps[f.Op] {
+			return errdetails.InvalidField("PKG.USER.INVALID_FILTER_OPERATOR", field+".op", "Unknown filter operator "+f.Op+", must be one of eq prefix contains")
+		}
+	}
+	for i, s := range q.Sort {
+		if !listFields[s.Field] {
+			return errdetails.InvalidField("PKG.USER.INVALID_SORT_FIELD", "sort["+strconv.Itoa(i)+"].field", "The users can not be sorted by "+s.Field)
+		}
+	}
+	if q.Limit < 0 || q.Limit > MaxLimit {
+		return errdetails.InvalidField("PKG.USER.INVALID_LIMIT", "limit", "The limit must be between 0 and "+strconv.Itoa(MaxLimit))
+	}
+	return nil
+}
+
+// repoQuery returns the query of the repo for the page of the cursor
+func (q ListQuery) repoQuery() (repo.Query, error) {
+	after, err := q.decodeCursor()
+	if err != nil {
+		return repo.Query{}, err
+	}
+
+	query := repo.Query{Filters: q.Filters, Sort: q.Sort, After: after, Limit: q.Limit}
+	if query.Limit == 0 {
+		query.Limit = DefaultLimit
+	}
+	return query, nil
+}
+
+// hash identifies the filters and the sorts of the query, the order of the filters does not matter
+func (q ListQuery) hash() string {
+	filters := make([]Filter, len(q.Filters))
+	copy(filters, q.Filters)
+	for i := range filters {
+		if filters[i].Op == "" {
+			filters[i].Op = db.OpEq
+		}
+	}
+	sort.Slice(filters, func(i, j int) bool {
+		a, b := filters[i], filters[j]
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		if a.Op != b.Op {
+			return a.Op < b.Op
+		}
+		return a.Value < b.Value
+	})
+
+	// Marshalling the slices of the structs does not fail
+	bytes, _ := json.Marshal([]interface{}{filters, q.Sort})
+	h := fnv.New64a()
+	h.Write(bytes)
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+// encodeCursor returns the opaque cursor of the page after the user in the users matching the query
+func (q ListQuery) encodeCursor(last *repo.User) string {
+	// Marshalling the cursor does not fail
+	bytes, _ := json.Marshal(cursor{After: repo.KeyOf(last, q.Sort), Query: q.hash()})
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// decodeCursor returns the key the page of the cursor starts after, nil if there is no cursor.
+// A cursor created for another query is rejected as its key is meaningless for this one
+func (q ListQuery) decodeCursor() (*repo.Key, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+
+	invalid := errdetails.InvalidField("PKG.USER.INVALID_CURSOR", "cursor", "The cursor is invalid")
+	bytes, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var c cursor
+	err = json.Unmarshal(bytes, &c)
+	if err != nil {
+		return nil, invalid
+	}
+	if c.Query != q.hash() {
+		return nil, errdetails.InvalidField("PKG.USER.CURSOR_QUERY_MISMATCH", "cursor", "The cursor was created for other filters or sorts")
+	}
+	if len(c.After.Values) != len(q.Sort) {
+		return nil, invalid
+	}
+	return &c.After, nil
+}
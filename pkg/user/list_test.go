@@ -0,0 +1,66 @@
+package user
+
+import (
+	"go-boilerplate-api/pkg/clients/db"
+	"go-boilerplate-api/pkg/user/repo"
+	"go-boilerplate-api/pkg/utils/errdetails"
+	"testing"
+
+	"github.com/ralstan-vaz/go-errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListQueryValidate(t *testing.T) {
+	valid := ListQuery{Filters: []Filter{{Field: "name", Op: db.OpPrefix, Value: "Sh"}}, Sort: []Sort{{Field: "id", Desc: true}}, Limit: MaxLimit}
+	assert.Nil(t, valid.validate())
+
+	// The invalid field of the query is in the details of the error
+	invalid := []struct {
+		q     ListQuery
+		field string
+	}{
+		{ListQuery{Filters: []Filter{{Field: "stars", Value: "5"}}}, "filters[0].field"},
+		{ListQuery{Filters: []Filter{{Field: "name", Value: "Sh"}, {Field: "name", Op: "like", Value: "Sh"}}}, "filters[1].op"},
+		{ListQuery{Sort: []Sort{{Field: "stars"}}}, "sort[0].field"},
+		{ListQuery{Limit: -1}, "limit"},
+		{ListQuery{Limit: MaxLimit + 1}, "limit"},
+	}
+	for _, c := range invalid {
+		err := c.q.validate()
+		assert.True(t, errors.IsBadRequest(err), "%+v", c.q)
+		if d := errdetails.Get(err); assert.NotNil(t, d) && assert.Len(t, d.Violations, 1) {
+			assert.Equal(t, c.field, d.Violations[0].Field)
+		}
+	}
+}
+
+func TestListQueryCursor(t *testing.T) {
+	q := ListQuery{Filters: []Filter{{Field: "name", Value: "Shepard"}, {Field: "id", Op: db.OpPrefix, Value: "1"}}, Sort: []Sort{{Field: "name", Desc: true}}}
+
+	// The first page has no cursor
+	query, err := q.repoQuery()
+	assert.Nil(t, err)
+	assert.Nil(t, query.After)
+	assert.Equal(t, DefaultLimit, query.Limit)
+
+	// The next page starts after the last user of the previous one
+	q.Cursor = q.encodeCursor(&repo.User{ID: "12", Name: "Shepard"})
+	query, err = q.repoQuery()
+	assert.Nil(t, err)
+	assert.Equal(t, &repo.Key{Values: []string{"Shepard"}, ID: "12"}, query.After)
+
+	// The order of the filters and the default operator do not change the query
+	reordered := ListQuery{Filters: []Filter{{Field: "id", Op: db.OpPrefix, Value: "1"}, {Field: "name", Op: db.OpEq, Value: "Shepard"}}, Sort: q.Sort, Cursor: q.Cursor}
+	query, err = reordered.repoQuery()
+	assert.Nil(t, err)
+	assert.Equal(t, &repo.Key{Values: []string{"Shepard"}, ID: "12"}, query.After)
+
+	// The cursor can not be used with other filters or sorts
+	other := ListQuery{Filters: q.Filters, Sort: []Sort{{Field: "id"}}, Cursor: q.Cursor}
+	_, err = other.repoQuery()
+	assert.True(t, errors.IsBadRequest(err))
+
+	q.Cursor = "not-a-cursor"
+	_, err = q.repoQuery()
+	assert.True(t, errors.IsBadRequest(err))
+}
@@ -1,5 +1,7 @@
 package user
 
+import "go-boilerplate-api/pkg/user/repo"
+
 // User contains all the properties of a user
 type User struct {
 	ID   string `json:"id,omitempty"`
@@ -15,6 +17,31 @@ type Favourite struct {
 	Beers []string `json:"beers,omitempty"`
 }
 
+// ListQuery selects a page of the users matching all the filters, in the order of the sorts and then of the IDs.
+// Cursor is the NextCursor of the previous page, the filters and the sorts must be the same as the ones of the previous page
+type ListQuery struct {
+	Filters []Filter
+	Sort    []Sort
+	// Limit is the maximum number of users of the page, DefaultLimit if it is 0
+	Limit  int
+	Cursor string
+}
+
+// Filter selects the users whose field matches the value with the operator, db.OpEq if there is none
+type Filter = repo.Filter
+
+// Sort orders the users by the field, in the descending order if Desc is set
+type Sort = repo.Sort
+
+// UserList is a page of users
+type UserList struct {
+	Users []*User `json:"users"`
+	// NextCursor gets the next page, it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Total is the number of users matching the filters across all the pages
+	Total int `json:"total"`
+}
+
 // // Create interfaces only where they are being used
 
 // // getter ..
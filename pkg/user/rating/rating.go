@@ -1,16 +1,21 @@
 package rating
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
+	"strconv"
 
 	"go-boilerplate-api/config"
 	httpPkg "go-boilerplate-api/pkg/clients/http"
+
+	"github.com/ralstan-vaz/go-errors"
 )
 
 // Rater is implemented by any value that contains the required methods
 type Rater interface {
-	Get(GetRequest) (*GetResponse, error)
+	Get(context.Context, GetRequest) (*GetResponse, error)
 }
 
 // NewRating creates a new instance of Rating
@@ -25,9 +30,9 @@ type Rating struct {
 }
 
 // Get makes the request to get the ratings
-func (r *Rating) Get(req GetRequest) (*GetResponse, error) {
+func (r *Rating) Get(ctx context.Context, req GetRequest) (*GetResponse, error) {
 
-	httpReq, err := r.httpRequester.New(r.config.Get().User.RatingsUrl)
+	httpReq, err := r.httpRequester.New(ctx, r.config.Get().User.RatingsUrl)
 	if err != nil {
 		return nil, err
 	}
@@ -40,6 +45,11 @@ func (r *Rating) Get(req GetRequest) (*GetResponse, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, statusError(resp.StatusCode)
+	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -53,3 +63,16 @@ func (r *Rating) Get(req GetRequest) (*GetResponse, error) {
 	}
 	return &res, nil
 }
+
+// statusError is the error of a failed response of the ratings, of the kind of its status code:
+// NotFound for a 404, BadRequest for the other 4xx and InternalError for the 5xx
+func statusError(code int) error {
+	description := "The ratings responded with " + strconv.Itoa(code) + " " + http.StatusText(code)
+	switch {
+	case code == http.StatusNotFound:
+		return errors.NewNotFound(description).SetCode("PKG.USER.RATING.NOT_FOUND")
+	case code < http.StatusInternalServerError:
+		return errors.NewBadRequest(description).SetCode("PKG.USER.RATING.BAD_REQUEST")
+	}
+	return errors.New(errors.Error{Kind: errors.InternalError, Code: "PKG.USER.RATING.SERVER_ERROR", Description: description})
+}
@@ -1,8 +1,17 @@
 package rating
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+
+	"go-boilerplate-api/config"
+	httpPkg "go-boilerplate-api/pkg/clients/http"
+
+	"github.com/ralstan-vaz/go-errors"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMain(m *testing.M) {
@@ -12,3 +21,66 @@ func TestMain(m *testing.M) {
 	// log.Println("Do stuff AFTER the tests!")
 	os.Exit(t)
 }
+
+// staticConfig is an IConfig that always returns the same config
+type staticConfig struct {
+	conf *config.Config
+}
+
+func (s *staticConfig) Get() *config.Config                        { return s.conf }
+func (s *staticConfig) Subscribe(fn func(old, new *config.Config)) {}
+func (s *staticConfig) Close() error                               { return nil }
+
+// newTestRating returns a Rating calling the ratings served by the handler
+func newTestRating(t *testing.T, handler http.HandlerFunc) (Rater, func()) {
+	server := httptest.NewServer(handler)
+
+	conf := &config.Config{}
+	conf.User.RatingsUrl = server.URL
+	staticConf := &staticConfig{conf: conf}
+	requester, err := httpPkg.NewRequest(staticConf, nil, nil)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return NewRating(staticConf, requester), server.Close
+}
+
+func TestGet(t *testing.T) {
+	r, closeServer := newTestRating(t, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"stars":"5"}`))
+	})
+	defer closeServer()
+
+	res, err := r.Get(context.Background(), GetRequest{ID: "1"})
+	assert.Nil(t, err)
+	assert.Equal(t, &GetResponse{Stars: "5"}, res)
+}
+
+func TestGetFailedResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   string
+		is     func(error) bool
+	}{
+		{name: "not found", status: http.StatusNotFound, code: "PKG.USER.RATING.NOT_FOUND", is: errors.IsNotFound},
+		{name: "bad request", status: http.StatusBadRequest, code: "PKG.USER.RATING.BAD_REQUEST", is: errors.IsBadRequest},
+		{name: "service unavailable", status: http.StatusServiceUnavailable, code: "PKG.USER.RATING.SERVER_ERROR", is: errors.IsInternalError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, closeServer := newTestRating(t, func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(test.status)
+			})
+			defer closeServer()
+
+			res, err := r.Get(context.Background(), GetRequest{ID: "1"})
+			assert.Nil(t, res)
+			assert.True(t, test.is(err))
+			assert.Equal(t, test.code, errors.Get(err).Code)
+		})
+	}
+}
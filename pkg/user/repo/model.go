@@ -1,13 +1,21 @@
 package repo
 
+import "go-boilerplate-api/pkg/clients/db"
+
 // User contains the properties stored in the repo
 type User struct {
 	ID   string `json:"Id,omitempty"`
 	Name string `json:"name,omitempty"`
 }
 
-// // dbInstancer is implemented by any value that contains the GetMyDB method.
-// // Makes it possible to inject an instance
-// type dbInstancer interface {
-// 	GetMyDB() db.ImyDB
-// }
+// Filter selects the users whose field matches the value with the operator
+type Filter = db.Filter
+
+// Sort orders the users by the field
+type Sort = db.Sort
+
+// Query selects a page of the users matching all the filters, in the order of the sorts and then of the IDs
+type Query = db.Query
+
+// Key is the position of a user in the order of the sorts of a query
+type Key = db.Key
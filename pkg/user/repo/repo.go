@@ -7,10 +7,13 @@ import (
 
 // UserRepoInterface ...
 type UserRepoInterface interface {
-	Get(query string) ([]*User, error)
+	List(q Query) ([]*User, int, error)
 	GetOne(id string) (*User, error)
 	GetAll() ([]*User, error)
-	Insert(u User) error
+	Insert(u User) (*User, error)
+	Update(id string, u User) error
+	Modify(id string, modify func(User) (User, error)) (*User, error)
+	Delete(id string) error
 }
 
 // NewUserRepo Create's an instance of a User Repository
@@ -24,16 +27,22 @@ type UserRepo struct {
 	db     db.MyDBInterface
 }
 
-// Get Gets users using a query
-func (ur *UserRepo) Get(query string) ([]*User, error) {
-	u := ur.db.Get(query)
+// List Gets a page of the users matching the query along with the number of users matching it
+func (ur *UserRepo) List(q Query) ([]*User, int, error) {
+	u, total, err := ur.db.List(q)
+	if err != nil {
+		return nil, 0, err
+	}
 	users := bindToUsers(u)
-	return users, nil
+	return users, total, nil
 }
 
-// GetOne Gets a user user an Id
+// GetOne Gets a user user an Id, a NotFound error is returned if there is no such user
 func (ur *UserRepo) GetOne(id string) (*User, error) {
-	u := ur.db.GetOne(id)
+	u, err := ur.db.GetOne(id)
+	if err != nil {
+		return nil, err
+	}
 	user := User(u)
 	return &user, nil
 }
@@ -45,10 +54,43 @@ func (ur *UserRepo) GetAll() ([]*User, error) {
 	return users, nil
 }
 
-// Insert Inserts a User
-func (ur *UserRepo) Insert(u User) error {
-	ur.db.Insert(u)
-	return nil
+// Insert Inserts a User and returns it with the Id assigned by the store
+func (ur *UserRepo) Insert(u User) (*User, error) {
+	id, err := ur.db.Insert(u)
+	if err != nil {
+		return nil, err
+	}
+	u.ID = id
+	return &u, nil
+}
+
+// Update Replaces the user with the Id, a NotFound error is returned if there is no such user
+func (ur *UserRepo) Update(id string, u User) error {
+	return ur.db.Update(id, u)
+}
+
+// Modify Replaces the user with the Id by the one modify returns from it, atomically, and returns the stored user.
+// A NotFound error is returned if there is no such user
+func (ur *UserRepo) Modify(id string, modify func(User) (User, error)) (*User, error) {
+	u, err := ur.db.Modify(id, func(current db.MimicUser) (db.MimicUser, error) {
+		modified, err := modify(User(current))
+		return db.MimicUser(modified), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	user := User(u)
+	return &user, nil
+}
+
+// Delete Deletes the user with the Id, a NotFound error is returned if there is no such user
+func (ur *UserRepo) Delete(id string) error {
+	return ur.db.Delete(id)
+}
+
+// KeyOf returns the key of the user in the order of the sorts
+func KeyOf(u *User, sorts []Sort) Key {
+	return db.KeyOf(db.MimicUser{ID: u.ID, Name: u.Name}, sorts)
 }
 
 func bindToUsers(u []db.MimicUser) []*User {
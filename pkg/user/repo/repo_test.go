@@ -1,10 +1,14 @@
 package repo
 
 import (
+	"context"
 	"go-boilerplate-api/pkg/clients/db"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/ralstan-vaz/go-errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -15,18 +19,18 @@ type MockStore struct {
 }
 
 // MOCKS -----------------
-func (m *MockStore) Get(query string) []db.MimicUser {
+func (m *MockStore) List(q db.Query) ([]db.MimicUser, int, error) {
 	// This allows us to pass in mocked results, so that the mock store will return whatever we define
-	returnVals := m.Called(query)
+	returnVals := m.Called(q)
 	// return the values which we define
-	return returnVals.Get(0).([]db.MimicUser)
+	return returnVals.Get(0).([]db.MimicUser), returnVals.Int(1), returnVals.Error(2)
 }
 
-func (m *MockStore) GetOne(id string) db.MimicUser {
+func (m *MockStore) GetOne(id string) (db.MimicUser, error) {
 	// This allows us to pass in mocked results, so that the mock store will return whatever we define
 	returnVals := m.Called(id)
 	// return the values which we define
-	return returnVals.Get(0).(db.MimicUser)
+	return returnVals.Get(0).(db.MimicUser), returnVals.Error(1)
 }
 
 func (m *MockStore) GetAll() []db.MimicUser {
@@ -36,10 +40,47 @@ func (m *MockStore) GetAll() []db.MimicUser {
 	return returnVals.Get(0).([]db.MimicUser)
 }
 
-func (m *MockStore) Insert(obj interface{}) error {
+func (m *MockStore) Insert(obj interface{}) (string, error) {
 	// This allows us to pass in mocked results, so that the mock store will return whatever we define
 	returnVals := m.Called(obj)
 	// return the values which we define
+	return returnVals.String(0), returnVals.Error(1)
+}
+
+func (m *MockStore) Update(id string, obj interface{}) error {
+	// This allows us to pass in mocked results, so that the mock store will return whatever we define
+	returnVals := m.Called(id, obj)
+	// return the values which we define
+	return returnVals.Error(0)
+}
+
+func (m *MockStore) Modify(id string, modify func(db.MimicUser) (db.MimicUser, error)) (db.MimicUser, error) {
+	// This allows us to pass in the stored user, which is then modified like the store would
+	returnVals := m.Called(id)
+	if err := returnVals.Error(1); err != nil {
+		return db.MimicUser{}, err
+	}
+	return modify(returnVals.Get(0).(db.MimicUser))
+}
+
+func (m *MockStore) Delete(id string) error {
+	// This allows us to pass in mocked results, so that the mock store will return whatever we define
+	returnVals := m.Called(id)
+	// return the values which we define
+	return returnVals.Error(0)
+}
+
+func (m *MockStore) Ping(ctx context.Context) error {
+	// This allows us to pass in mocked results, so that the mock store will return whatever we define
+	returnVals := m.Called(ctx)
+	// return the values which we define
+	return returnVals.Error(0)
+}
+
+func (m *MockStore) Close() error {
+	// This allows us to pass in mocked results, so that the mock store will return whatever we define
+	returnVals := m.Called()
+	// return the values which we define
 	return returnVals.Error(0)
 }
 
@@ -70,24 +111,26 @@ func TestMain(m *testing.M) {
 	os.Exit(t)
 }
 
-func TestGetSuccess(t *testing.T) {
-	var query = "111"
+func TestListSuccess(t *testing.T) {
+	var query = Query{Filters: []Filter{{Field: "name", Op: "prefix", Value: "Sh"}}, Sort: []Sort{{Field: "name", Desc: true}}, After: &Key{Values: []string{"Shepard"}, ID: "1"}, Limit: 1}
+	var dbQuery = db.Query{Filters: []db.Filter{{Field: "name", Op: "prefix", Value: "Sh"}}, Sort: []db.Sort{{Field: "name", Desc: true}}, After: &db.Key{Values: []string{"Shepard"}, ID: "1"}, Limit: 1}
 	var repoUsers = []*User{{ID: "111", Name: "Shourie"}}
 
 	// Defines input and return type
-	m.On("Get", query).Return(mUsers)
+	m.On("List", dbQuery).Return(mUsers, 11, nil)
 
 	// Next, we create a new instance of our module with the mock store as its "Favourite" dependency
 	repo := UserRepo{nil, m}
 
 	// Calls the actual module function
-	resp, err := repo.Get(query)
+	resp, total, err := repo.List(query)
 
 	// The expectations that we defined for our mock store earlier are asserted here
 	m.AssertExpectations(t)
 
 	// Assert response object
 	success := assert.Equal(t, repoUsers, resp)
+	assert.Equal(t, 11, total)
 
 	// Finally, we assert that we should'nt get any error
 	if err != nil {
@@ -104,7 +147,7 @@ func TestGetOneSuccess(t *testing.T) {
 	var repoUser = &User{ID: "111", Name: "Shourie"}
 
 	// Defines input and return type
-	m.On("GetOne", query).Return(mUser)
+	m.On("GetOne", query).Return(mUser, nil)
 
 	// Next, we create a new instance of our module with the mock store as its "Favourite" dependency
 	repo := UserRepo{nil, m}
@@ -157,16 +200,142 @@ func TestGetAllSuccess(t *testing.T) {
 }
 
 func TestInsertSuccess(t *testing.T) {
+	var repoUser = User{Name: "Shourie"}
+
+	// Defines input and return type, the store assigns the Id
+	m.On("Insert", repoUser).Return("111", nil)
+
+	// Next, we create a new instance of our module with the mock store as its "Favourite" dependency
+	repo := UserRepo{nil, m}
+
+	// Calls the actual module function
+	resp, err := repo.Insert(repoUser)
+
+	// The expectations that we defined for our mock store earlier are asserted here
+	m.AssertExpectations(t)
+
+	// Assert response object
+	assert.Equal(t, &User{ID: "111", Name: "Shourie"}, resp)
+
+	// Finally, we assert that we should'nt get any error
+	if err != nil {
+		t.Errorf("error should be nil, got: %v", err)
+	}
+}
+
+func TestGetOneNotFound(t *testing.T) {
+	var query = "404"
+
+	// Defines input and return type
+	m.On("GetOne", query).Return(db.MimicUser{}, errors.NewNotFound("User 404 does not exist"))
+
+	// Next, we create a new instance of our module with the mock store as its "Favourite" dependency
+	repo := UserRepo{nil, m}
+
+	// Calls the actual module function
+	resp, err := repo.GetOne(query)
+
+	// The expectations that we defined for our mock store earlier are asserted here
+	m.AssertExpectations(t)
+
+	// The user is not made up when the store does not have it
+	assert.Nil(t, resp)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestUpdateSuccess(t *testing.T) {
 	var repoUser = User{ID: "111", Name: "Shourie"}
 
 	// Defines input and return type
-	m.On("Insert", repoUser).Return(nil)
+	m.On("Update", "111", repoUser).Return(nil)
+
+	// Next, we create a new instance of our module with the mock store as its "Favourite" dependency
+	repo := UserRepo{nil, m}
+
+	// Calls the actual module function
+	err := repo.Update("111", repoUser)
+
+	// The expectations that we defined for our mock store earlier are asserted here
+	m.AssertExpectations(t)
+
+	// Finally, we assert that we should'nt get any error
+	if err != nil {
+		t.Errorf("error should be nil, got: %v", err)
+	}
+}
+
+func TestModifyConcurrent(t *testing.T) {
+	// The in-memory store is used so that the writes actually race
+	repo := UserRepo{nil, db.NewMyDB()}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Modify("1", func(u User) (User, error) {
+				u.Name += "!"
+				return u, nil
+			})
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// None of the modifications is lost
+	u, err := repo.GetOne("1")
+	assert.Nil(t, err)
+	assert.Equal(t, "Shepard"+strings.Repeat("!", 50), u.Name)
+
+	// Nothing is stored when the modification fails
+	_, err = repo.Modify("1", func(u User) (User, error) {
+		return User{Name: "Wrex"}, errors.NewBadRequest("invalid")
+	})
+	assert.True(t, errors.IsBadRequest(err))
+	u, _ = repo.GetOne("1")
+	assert.Equal(t, "1", u.ID)
+	assert.NotEqual(t, "Wrex", u.Name)
+
+	_, err = repo.Modify("404", func(u User) (User, error) { return u, nil })
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestListAfterKey(t *testing.T) {
+	// The in-memory store is used so that the users change between the pages
+	repo := UserRepo{nil, db.NewMyDB()}
+	sorts := []Sort{{Field: "name", Desc: true}}
+
+	// Tali, Shepard, Miranda
+	page, total, err := repo.List(Query{Sort: sorts, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []*User{{ID: "3", Name: "Tali"}}, page)
+
+	// A user inserted before the cursor and the deletion of the first page do not shift the next page
+	_, err = repo.Insert(User{Name: "Wrex"})
+	assert.Nil(t, err)
+	assert.Nil(t, repo.Delete("3"))
+	page, _, err = repo.List(Query{Sort: sorts, After: &Key{Values: []string{"Tali"}, ID: "3"}, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, []*User{{ID: "1", Name: "Shepard"}}, page)
+
+	// The IDs break the ties of the sorts
+	key := KeyOf(page[0], sorts)
+	assert.Equal(t, Key{Values: []string{"Shepard"}, ID: "1"}, key)
+	page, _, err = repo.List(Query{Sort: sorts, After: &key})
+	assert.Nil(t, err)
+	assert.Equal(t, []*User{{ID: "2", Name: "Miranda"}}, page)
+}
+
+func TestDeleteSuccess(t *testing.T) {
+	// Defines input and return type
+	m.On("Delete", "111").Return(nil)
 
 	// Next, we create a new instance of our module with the mock store as its "Favourite" dependency
 	repo := UserRepo{nil, m}
 
 	// Calls the actual module function
-	err := repo.Insert(repoUser)
+	err := repo.Delete("111")
 
 	// The expectations that we defined for our mock store earlier are asserted here
 	m.AssertExpectations(t)
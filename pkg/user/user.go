@@ -1,22 +1,36 @@
 package user
 
 import (
+	"context"
+	"encoding/json"
 	"go-boilerplate-api/apm"
 	"go-boilerplate-api/config"
 	"go-boilerplate-api/pkg/user/favourite"
 	"go-boilerplate-api/pkg/user/rating"
 	"go-boilerplate-api/pkg/user/repo"
+	"go-boilerplate-api/pkg/utils/errdetails"
+	"go-boilerplate-api/pkg/utils/mergepatch"
+	"strconv"
+	"time"
 
 	"github.com/ralstan-vaz/go-errors"
 )
 
+// UpstreamRetryDelay is the delay after which a request failed by the ratings or the favourites can be retried
+const UpstreamRetryDelay = time.Second
+
 // UsersInterface ...
 type UsersInterface interface {
-	Get(query string) ([]*User, error)
-	GetOne(id string) (*User, error)
-	GetAll() ([]*User, error)
-	Insert(u User) error
-	GetWithInfo(id string) (*User, error)
+	List(ctx context.Context, q ListQuery) (*UserList, error)
+	Export(ctx context.Context, q ListQuery, send func(*User) error) error
+	GetOne(ctx context.Context, id string) (*User, error)
+	GetAll(ctx context.Context) ([]*User, error)
+	Insert(ctx context.Context, u User) (*User, error)
+	Update(ctx context.Context, id string, u User) (*User, error)
+	Patch(ctx context.Context, id string, patch []byte) (*User, error)
+	Delete(ctx context.Context, id string) error
+	GetWithInfo(ctx context.Context, id string) (*User, error)
+	BatchGet(ctx context.Context, ids []string) ([]*User, error)
 }
 
 // NewUser creates an instance of Users using the dependencies passed
@@ -34,18 +48,69 @@ type Users struct {
 	apm       apm.HandlerInterface
 }
 
-// Get gets users from the store using the query passed
-func (pkg *Users) Get(query string) ([]*User, error) {
-	repoUsers, err := pkg.user.Get(query)
+// List gets a page of the users matching the query along with the cursor of the next page
+func (pkg *Users) List(ctx context.Context, q ListQuery) (*UserList, error) {
+	err := q.validate()
 	if err != nil {
 		return nil, err
 	}
-	users := bindToUsers(repoUsers)
-	return users, nil
+
+	query, err := q.repoQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	// One user more than the limit is read to know if there is a next page
+	limit := query.Limit
+	query.Limit++
+	repoUsers, total, err := pkg.user.List(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var next string
+	if len(repoUsers) > limit {
+		repoUsers = repoUsers[:limit]
+		next = q.encodeCursor(repoUsers[limit-1])
+	}
+	return &UserList{Users: bindToUsers(repoUsers), NextCursor: next, Total: total}, nil
+}
+
+// Export sends all the users matching the filters of the query, in the order of its sorts.
+// The users are read one page of the limit at a time, MaxLimit by default, so that they are never all loaded at once.
+// It stops at the first error of send or once the context is done, returning the error of the context
+func (pkg *Users) Export(ctx context.Context, q ListQuery, send func(*User) error) error {
+	q.Cursor = ""
+	if q.Limit == 0 {
+		q.Limit = MaxLimit
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		page, err := pkg.List(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range page.Users {
+			err = send(u)
+			if err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		q.Cursor = page.NextCursor
+	}
 }
 
 // GetOne gets a user from the store using the query
-func (pkg *Users) GetOne(id string) (*User, error) {
+func (pkg *Users) GetOne(ctx context.Context, id string) (*User, error) {
 	repoUser, err := pkg.user.GetOne(id)
 	if err != nil {
 		return nil, err
@@ -54,8 +119,25 @@ func (pkg *Users) GetOne(id string) (*User, error) {
 	return user, nil
 }
 
+// BatchGet gets the users in the order of the ids, a NotFound error is returned if any of them does not exist
+func (pkg *Users) BatchGet(ctx context.Context, ids []string) ([]*User, error) {
+	if len(ids) > MaxLimit {
+		return nil, errdetails.InvalidField("PKG.USER.TOO_MANY_IDS", "ids", "At most "+strconv.Itoa(MaxLimit)+" users can be got at once")
+	}
+
+	users := make([]*User, 0, len(ids))
+	for _, id := range ids {
+		repoUser, err := pkg.user.GetOne(id)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, bindToUser(repoUser))
+	}
+	return users, nil
+}
+
 // GetAll gets all the users
-func (pkg *Users) GetAll() ([]*User, error) {
+func (pkg *Users) GetAll(ctx context.Context) ([]*User, error) {
 	repoUsers, err := pkg.user.GetAll()
 	if err != nil {
 		return nil, err
@@ -64,36 +146,99 @@ func (pkg *Users) GetAll() ([]*User, error) {
 	return users, nil
 }
 
-// Insert stores a user
-func (pkg *Users) Insert(u User) error {
+// Insert stores a user and returns it with the id assigned by the store, the id of the user is ignored
+func (pkg *Users) Insert(ctx context.Context, u User) (*User, error) {
 
 	user := repo.User{
-		ID:   u.ID,
 		Name: u.Name,
 	}
-	err := pkg.user.Insert(user)
+	repoUser, err := pkg.user.Insert(user)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return bindToUser(repoUser), nil
+}
+
+// Update replaces the stored properties of the user with the id and returns the updated user.
+// The id of the user, if set, must be the same as the id
+func (pkg *Users) Update(ctx context.Context, id string, u User) (*User, error) {
+	user, err := toRepoUser(id, u)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pkg.user.Update(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return bindToUser(&user), nil
+}
+
+// Patch applies a JSON Merge Patch (RFC 7386) to the stored properties of the user with the id and returns the updated user.
+// The user is read, patched and stored atomically so that concurrent patches are not lost
+func (pkg *Users) Patch(ctx context.Context, id string, patch []byte) (*User, error) {
+	updated, err := pkg.user.Modify(id, func(current repo.User) (repo.User, error) {
+		doc, err := json.Marshal(bindToUser(&current))
+		if err != nil {
+			return repo.User{}, errors.NewInternalError(err).SetCode("PKG.USER.ENCODE_FAILED")
+		}
+
+		patched, err := mergepatch.Apply(doc, patch)
+		if err != nil {
+			return repo.User{}, err
+		}
+
+		var u User
+		err = json.Unmarshal(patched, &u)
+		if err != nil {
+			return repo.User{}, errors.NewBadRequest("The patched user is invalid : " + err.Error()).SetCode("PKG.USER.INVALID_PATCH")
+		}
+
+		return toRepoUser(id, u)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bindToUser(updated), nil
+}
+
+// toRepoUser validates the properties of the user with the id and converts them to the stored user.
+// The id of the user, if set, must be the same as the id
+func toRepoUser(id string, u User) (repo.User, error) {
+	if u.ID != "" && u.ID != id {
+		return repo.User{}, errdetails.InvalidField("PKG.USER.ID_MISMATCH", "id", "The id of the user "+u.ID+" does not match "+id)
+	}
+	if u.Name == "" {
+		return repo.User{}, errdetails.InvalidField("PKG.USER.NAME_REQUIRED", "name", "The name of the user is required")
 	}
 
-	return nil
+	return repo.User{ID: id, Name: u.Name}, nil
 }
 
-// GetWithInfo get a user from the store along with the ratings and favourites
-func (pkg *Users) GetWithInfo(id string) (*User, error) {
+// Delete deletes the user with the id
+func (pkg *Users) Delete(ctx context.Context, id string) error {
+	return pkg.user.Delete(id)
+}
+
+// GetWithInfo get a user from the store along with the ratings and favourites.
+// The context carries the apm transaction the calls to the ratings and the favourites are traced in
+func (pkg *Users) GetWithInfo(ctx context.Context, id string) (*User, error) {
 	repoUser, err := pkg.user.GetOne(id)
 	if err != nil {
-		return nil, errors.NewInternalError(err)
+		return nil, err
 	}
 
-	rating, err := pkg.rating.Get(rating.GetRequest{ID: id})
+	rating, err := pkg.rating.Get(ctx, rating.GetRequest{ID: id})
 	if err != nil {
-		return nil, errors.NewInternalError(err)
+		return nil, upstreamError(err, "PKG.USER.RATING_UNAVAILABLE")
 	}
 
-	favourite, err := pkg.favourite.Get(favourite.GetRequest{ID: id})
+	favourite, err := pkg.favourite.Get(ctx, favourite.GetRequest{ID: id})
 	if err != nil {
-		return nil, errors.NewInternalError(err)
+		return nil, upstreamError(err, "PKG.USER.FAVOURITE_UNAVAILABLE")
 	}
 
 	fav := Favourite{Beers: favourite.Beers}
@@ -107,6 +252,16 @@ func (pkg *Users) GetWithInfo(id string) (*User, error) {
 	return user, nil
 }
 
+// upstreamError returns the error of a failed call to the ratings or the favourites.
+// The internal errors, ie. the transport failures, the 5xx responses and the unavailable services, are returned with the code
+// and can be retried after UpstreamRetryDelay, the other errors keep their kind, eg. NotFound or BadRequest
+func upstreamError(err error, code string) error {
+	if errors.Get(err).Kind != errors.InternalError {
+		return err
+	}
+	return errdetails.WithRetry(errors.NewInternalError(err).SetCode(code), UpstreamRetryDelay)
+}
+
 func bindToUsers(u []*repo.User) []*User {
 	user := []*User{}
 	for i := 0; i < len(u); i++ {
@@ -1,12 +1,20 @@
 package user
 
 import (
+	"context"
+	ierror "errors"
+	"go-boilerplate-api/config"
+	httpPkg "go-boilerplate-api/pkg/clients/http"
 	"go-boilerplate-api/pkg/user/favourite"
 	"go-boilerplate-api/pkg/user/rating"
 	"go-boilerplate-api/pkg/user/repo"
+	"go-boilerplate-api/pkg/utils/errdetails"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
+	"github.com/ralstan-vaz/go-errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -24,11 +32,11 @@ type MockStoreFavourite struct {
 }
 
 // REPO MOCKS
-func (m *MockStoreRepo) Get(query string) ([]*repo.User, error) {
+func (m *MockStoreRepo) List(q repo.Query) ([]*repo.User, int, error) {
 	// This allows us to pass in mocked results, so that the mock store will return whatever we define
-	returnVals := m.Called(query)
+	returnVals := m.Called(q)
 	// return the values which we define
-	return returnVals.Get(0).([]*repo.User), returnVals.Error(1)
+	return returnVals.Get(0).([]*repo.User), returnVals.Int(1), returnVals.Error(2)
 }
 
 func (m *MockStoreRepo) GetOne(id string) (*repo.User, error) {
@@ -45,15 +53,42 @@ func (m *MockStoreRepo) GetAll() ([]*repo.User, error) {
 	return returnVals.Get(0).([]*repo.User), returnVals.Error(1)
 }
 
-func (m *MockStoreRepo) Insert(u repo.User) error {
+func (m *MockStoreRepo) Insert(u repo.User) (*repo.User, error) {
 	// This allows us to pass in mocked results, so that the mock store will return whatever we define
 	returnVals := m.Called(u)
 	// return the values which we define
+	return returnVals.Get(0).(*repo.User), returnVals.Error(1)
+}
+
+func (m *MockStoreRepo) Update(id string, u repo.User) error {
+	// This allows us to pass in mocked results, so that the mock store will return whatever we define
+	returnVals := m.Called(id, u)
+	// return the values which we define
+	return returnVals.Error(0)
+}
+
+func (m *MockStoreRepo) Modify(id string, modify func(repo.User) (repo.User, error)) (*repo.User, error) {
+	// This allows us to pass in the stored user, which is then modified like the store would
+	returnVals := m.Called(id)
+	if err := returnVals.Error(1); err != nil {
+		return nil, err
+	}
+	u, err := modify(*returnVals.Get(0).(*repo.User))
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (m *MockStoreRepo) Delete(id string) error {
+	// This allows us to pass in mocked results, so that the mock store will return whatever we define
+	returnVals := m.Called(id)
+	// return the values which we define
 	return returnVals.Error(0)
 }
 
 // RATING MOCKS
-func (m *MockStoreRating) Get(req rating.GetRequest) (*rating.GetResponse, error) {
+func (m *MockStoreRating) Get(ctx context.Context, req rating.GetRequest) (*rating.GetResponse, error) {
 	// This allows us to pass in mocked results, so that the mock store will return whatever we define
 	returnVals := m.Called(req)
 	// return the values which we define
@@ -61,7 +96,7 @@ func (m *MockStoreRating) Get(req rating.GetRequest) (*rating.GetResponse, error
 }
 
 // FAVOURITE MOCKS
-func (m *MockStoreFavourite) Get(req favourite.GetRequest) (*favourite.GetResponse, error) {
+func (m *MockStoreFavourite) Get(ctx context.Context, req favourite.GetRequest) (*favourite.GetResponse, error) {
 	// This allows us to pass in mocked results, so that the mock store will return whatever we define
 	returnVals := m.Called(req)
 	// return the values which we define
@@ -95,32 +130,41 @@ func TestMain(m *testing.M) {
 	os.Exit(t)
 }
 
-func TestGetSuccess(t *testing.T) {
-	var query = "111"
-	var users = []*User{{ID: "111", Name: "Shourie"}}
+func TestListSuccess(t *testing.T) {
+	var query = ListQuery{Filters: []Filter{{Field: "name", Value: "Shourie"}}, Limit: 1}
+	var list = &UserList{Users: []*User{{ID: "111", Name: "Shourie"}}, Total: 2}
 
-	// Defines input and return type
-	m.On("Get", query).Return(repoUsers, nil)
+	// Defines input and return type, the first page starts at the first user and one more user is read to know if there is a next page
+	m.On("List", repo.Query{Filters: []repo.Filter{{Field: "name", Value: "Shourie"}}, Limit: 2}).Return([]*repo.User{repoUsers[0], {ID: "112", Name: "Shourie"}}, 2, nil)
 
 	// Next, we create a new instance of our module with the mock store as its "Favourite" dependency
 	s := Users{nil, m, nil, nil, nil}
 
 	// Calls the actual module function
-	resp, err := s.Get(query)
+	resp, err := s.List(context.Background(), query)
 
 	// The expectations that we defined for our mock store earlier are asserted here
 	m.AssertExpectations(t)
 
-	// Assert response object
-	success := assert.Equal(t, users, resp)
-
 	// Finally, we assert that we should'nt get any error
 	if err != nil {
 		t.Errorf("error should be nil, got: %v", err)
 	}
 
-	if !success {
-		t.Errorf("assert failed, result should be same : res = %v  resp = %v", users, resp)
+	// The cursor of the next page is returned as one user is left
+	if assert.NotNil(t, resp) && assert.NotEmpty(t, resp.NextCursor) {
+		success := assert.Equal(t, list.Users, resp.Users)
+		assert.Equal(t, list.Total, resp.Total)
+		if !success {
+			t.Errorf("assert failed, result should be same : res = %v  resp = %v", list, resp)
+		}
+
+		// The next page starts after the first user and is the last one
+		m.On("List", repo.Query{Filters: []repo.Filter{{Field: "name", Value: "Shourie"}}, After: &repo.Key{ID: "111"}, Limit: 2}).Return([]*repo.User{{ID: "112", Name: "Shourie"}}, 2, nil)
+		query.Cursor = resp.NextCursor
+		resp, err = s.List(context.Background(), query)
+		assert.Nil(t, err)
+		assert.Empty(t, resp.NextCursor)
 	}
 }
 
@@ -135,7 +179,7 @@ func TestGetOneSuccess(t *testing.T) {
 	s := Users{nil, m, nil, nil, nil}
 
 	// Calls the actual module function
-	resp, err := s.GetOne(query)
+	resp, err := s.GetOne(context.Background(), query)
 
 	// The expectations that we defined for our mock store earlier are asserted here
 	m.AssertExpectations(t)
@@ -163,7 +207,7 @@ func TestGetAllSuccess(t *testing.T) {
 	s := Users{nil, m, nil, nil, nil}
 
 	// Calls the actual module function
-	resp, err := s.GetAll()
+	resp, err := s.GetAll(context.Background())
 
 	// The expectations that we defined for our mock store earlier are asserted here
 	m.AssertExpectations(t)
@@ -182,20 +226,24 @@ func TestGetAllSuccess(t *testing.T) {
 }
 
 func TestInsertSuccess(t *testing.T) {
-	var user = User{ID: "111", Name: "Shourie"}
+	// The id sent is ignored, the store assigns one
+	var user = User{ID: "999", Name: "Shourie"}
 
 	// Defines input and return type
-	m.On("Insert", *repoUser).Return(nil)
+	m.On("Insert", repo.User{Name: "Shourie"}).Return(repoUser, nil)
 
 	// Next, we create a new instance of our module with the mock store as its "Favourite" dependency
 	s := Users{nil, m, nil, nil, nil}
 
 	// Calls the actual module function
-	err := s.Insert(user)
+	resp, err := s.Insert(context.Background(), user)
 
 	// The expectations that we defined for our mock store earlier are asserted here
 	m.AssertExpectations(t)
 
+	// Assert response object
+	assert.Equal(t, &User{ID: "111", Name: "Shourie"}, resp)
+
 	// Finally, we assert that we should'nt get any error
 	if err != nil {
 		t.Errorf("error should be nil, got: %v", err)
@@ -224,7 +272,7 @@ func TestGetWithInfoSuccess(t *testing.T) {
 	s := Users{nil, m, m2, m3, nil}
 
 	// Calls the actual module function
-	resp, err := s.GetWithInfo(id)
+	resp, err := s.GetWithInfo(context.Background(), id)
 
 	// The expectations that we defined for our mock store earlier are asserted here
 	m.AssertExpectations(t)
@@ -244,4 +292,247 @@ func TestGetWithInfoSuccess(t *testing.T) {
 	}
 }
 
+func TestGetWithInfoRatingUnavailable(t *testing.T) {
+	var id = "111"
+
+	// Create specific mocks objects only for this test
+	m2 := new(MockStoreRating)
+
+	// Defines input and return type, the ratings fail
+	m.On("GetOne", id).Return(repoUser, nil)
+	m2.On("Get", rating.GetRequest{ID: id}).Return((*rating.GetResponse)(nil), errors.NewInternalError(ierror.New("connection refused")))
+
+	s := Users{nil, m, m2, nil, nil}
+
+	_, err := s.GetWithInfo(context.Background(), id)
+	m2.AssertExpectations(t)
+
+	// The request can be retried once the ratings are back
+	assert.Equal(t, "PKG.USER.RATING_UNAVAILABLE", errors.Get(err).Code)
+	if d := errdetails.Get(err); assert.NotNil(t, d) {
+		assert.Equal(t, UpstreamRetryDelay, d.RetryDelay)
+	}
+
+	// The other errors of the ratings keep their kind and are not retried
+	notFound := errors.NewNotFound("The ratings responded with 404 Not Found").SetCode("PKG.USER.RATING.NOT_FOUND")
+	m2.ExpectedCalls = nil
+	m2.On("Get", rating.GetRequest{ID: id}).Return((*rating.GetResponse)(nil), notFound)
+
+	_, err = s.GetWithInfo(context.Background(), id)
+	assert.True(t, errors.IsNotFound(err))
+	assert.Equal(t, "PKG.USER.RATING.NOT_FOUND", errors.Get(err).Code)
+	assert.Nil(t, errdetails.Get(err))
+}
+
+// staticConfig is an IConfig that always returns the same config
+type staticConfig struct {
+	conf *config.Config
+}
+
+func (s *staticConfig) Get() *config.Config                        { return s.conf }
+func (s *staticConfig) Subscribe(fn func(old, new *config.Config)) {}
+func (s *staticConfig) Close() error                               { return nil }
+
+func TestUpstreamErrorOfRatingResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   string
+		retry  bool
+	}{
+		{name: "not found", status: http.StatusNotFound, code: "PKG.USER.RATING.NOT_FOUND", retry: false},
+		{name: "bad request", status: http.StatusBadRequest, code: "PKG.USER.RATING.BAD_REQUEST", retry: false},
+		{name: "service unavailable", status: http.StatusServiceUnavailable, code: "PKG.USER.RATING_UNAVAILABLE", retry: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			conf := &config.Config{}
+			conf.User.RatingsUrl = server.URL
+			staticConf := &staticConfig{conf: conf}
+			requester, err := httpPkg.NewRequest(staticConf, nil, nil)
+			assert.Nil(t, err)
+
+			_, err = rating.NewRating(staticConf, requester).Get(context.Background(), rating.GetRequest{ID: "1"})
+			err = upstreamError(err, "PKG.USER.RATING_UNAVAILABLE")
+
+			// Only the failures of the ratings themselves can be retried
+			assert.Equal(t, test.code, errors.Get(err).Code)
+			if !test.retry {
+				assert.Nil(t, errdetails.Get(err))
+				return
+			}
+			assert.True(t, errors.IsInternalError(err))
+			if d := errdetails.Get(err); assert.NotNil(t, d) {
+				assert.Equal(t, UpstreamRetryDelay, d.RetryDelay)
+			}
+		})
+	}
+}
+
+func TestUpdateSuccess(t *testing.T) {
+	var user = User{Name: "Garrus"}
+	var updated = &User{ID: "222", Name: "Garrus"}
+
+	// Defines input and return type, the id of the path is stored
+	m.On("Update", "222", repo.User{ID: "222", Name: "Garrus"}).Return(nil)
+
+	// Next, we create a new instance of our module with the mock store as its "Favourite" dependency
+	s := Users{nil, m, nil, nil, nil}
+
+	// Calls the actual module function
+	resp, err := s.Update(context.Background(), "222", user)
+
+	// The expectations that we defined for our mock store earlier are asserted here
+	m.AssertExpectations(t)
+
+	assert.Nil(t, err)
+	assert.Equal(t, updated, resp)
+}
+
+func TestUpdateInvalid(t *testing.T) {
+	s := Users{nil, m, nil, nil, nil}
+
+	// The id of the user can not be changed
+	_, err := s.Update(context.Background(), "222", User{ID: "333", Name: "Garrus"})
+	assert.True(t, errors.IsBadRequest(err))
+
+	// The name is required
+	_, err = s.Update(context.Background(), "222", User{})
+	assert.True(t, errors.IsBadRequest(err))
+	if d := errdetails.Get(err); assert.NotNil(t, d) {
+		assert.Equal(t, []errdetails.Violation{{Field: "name", Description: "The name of the user is required"}}, d.Violations)
+	}
+}
+
+func TestPatchSuccess(t *testing.T) {
+	// Create specific mocks objects only for this test
+	m2 := new(MockStoreRepo)
+
+	// Defines input and return type
+	// The stored user is patched within the modification
+	m2.On("Modify", "111").Return(repoUser, nil)
+
+	s := Users{nil, m2, nil, nil, nil}
+
+	// Calls the actual module function
+	resp, err := s.Patch(context.Background(), "111", []byte(`{"name":"Wrex"}`))
+
+	// The expectations that we defined for our mock store earlier are asserted here
+	m2.AssertExpectations(t)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &User{ID: "111", Name: "Wrex"}, resp)
+
+	// Removing the name leaves the user invalid
+	_, err = s.Patch(context.Background(), "111", []byte(`{"name":null}`))
+	assert.True(t, errors.IsBadRequest(err))
+}
+
+func TestPatchNotFound(t *testing.T) {
+	// Create specific mocks objects only for this test
+	m2 := new(MockStoreRepo)
+
+	// Defines input and return type
+	m2.On("Modify", "404").Return((*repo.User)(nil), errors.NewNotFound("User 404 does not exist"))
+
+	s := Users{nil, m2, nil, nil, nil}
+
+	// Calls the actual module function
+	resp, err := s.Patch(context.Background(), "404", []byte(`{"name":"Wrex"}`))
+
+	// The expectations that we defined for our mock store earlier are asserted here
+	m2.AssertExpectations(t)
+
+	assert.Nil(t, resp)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestExport(t *testing.T) {
+	// Create specific mocks objects only for this test
+	m2 := new(MockStoreRepo)
+
+	// Defines input and return type, the users are read one page at a time
+	m2.On("List", repo.Query{Limit: 2}).Return([]*repo.User{{ID: "1", Name: "Shepard"}, {ID: "2", Name: "Miranda"}}, 2, nil)
+	m2.On("List", repo.Query{After: &repo.Key{ID: "1"}, Limit: 2}).Return([]*repo.User{{ID: "2", Name: "Miranda"}}, 2, nil)
+
+	s := Users{nil, m2, nil, nil, nil}
+
+	// Calls the actual module function
+	var sent []*User
+	err := s.Export(context.Background(), ListQuery{Limit: 1}, func(u *User) error {
+		sent = append(sent, u)
+		return nil
+	})
+
+	// The expectations that we defined for our mock store earlier are asserted here
+	m2.AssertExpectations(t)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*User{{ID: "1", Name: "Shepard"}, {ID: "2", Name: "Miranda"}}, sent)
+
+	// The export stops at the first error of send
+	sendErr := errors.NewInternalError(context.Canceled)
+	calls := 0
+	err = s.Export(context.Background(), ListQuery{Limit: 1}, func(u *User) error {
+		calls++
+		return sendErr
+	})
+	assert.Equal(t, sendErr, err)
+	assert.Equal(t, 1, calls)
+
+	// The export stops once the context is done, with its error
+	ctx, cancel := context.WithCancel(context.Background())
+	err = s.Export(ctx, ListQuery{Limit: 1}, func(u *User) error {
+		cancel()
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestBatchGet(t *testing.T) {
+	// Create specific mocks objects only for this test
+	m2 := new(MockStoreRepo)
+
+	// Defines input and return type
+	m2.On("GetOne", "2").Return(&repo.User{ID: "2", Name: "Miranda"}, nil)
+	m2.On("GetOne", "1").Return(&repo.User{ID: "1", Name: "Shepard"}, nil)
+	m2.On("GetOne", "404").Return((*repo.User)(nil), errors.NewNotFound("User 404 does not exist"))
+
+	s := Users{nil, m2, nil, nil, nil}
+
+	// The users are in the order of the ids
+	resp, err := s.BatchGet(context.Background(), []string{"2", "1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []*User{{ID: "2", Name: "Miranda"}, {ID: "1", Name: "Shepard"}}, resp)
+
+	// No user is returned if any of them does not exist
+	resp, err = s.BatchGet(context.Background(), []string{"1", "404"})
+	assert.Nil(t, resp)
+	assert.True(t, errors.IsNotFound(err))
+
+	_, err = s.BatchGet(context.Background(), make([]string, MaxLimit+1))
+	assert.True(t, errors.IsBadRequest(err))
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	// Defines input and return type
+	m.On("Delete", "404").Return(errors.NewNotFound("User 404 does not exist"))
+
+	s := Users{nil, m, nil, nil, nil}
+
+	// Calls the actual module function
+	err := s.Delete(context.Background(), "404")
+
+	// The expectations that we defined for our mock store earlier are asserted here
+	m.AssertExpectations(t)
+
+	assert.True(t, errors.IsNotFound(err))
+}
+
 ///////
@@ -0,0 +1,105 @@
+// Package errdetails attaches to the errors the details the clients can act on: the invalid fields of a bad request
+// and the delay after which a failed request can be retried. The details are carried as the source error of the error,
+// so that they reach the protocol layers through the returned errors, where they are sent eg. as google.rpc.Status details
+package errdetails
+
+import (
+	"strings"
+	"time"
+
+	"go-boilerplate-api/pkg/utils/redact"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// Violation describes a field of the request that is invalid
+type Violation struct {
+	Field       string
+	Description string
+}
+
+// Details are the details of an error
+type Details struct {
+	// Violations are the invalid fields of a bad request
+	Violations []Violation
+	// RetryDelay is the delay after which the request can be retried, 0 if it should not be retried as it is
+	RetryDelay time.Duration
+	// Metadata is the additional information of the error, eg. the one received from a server
+	Metadata map[string]string
+	// Cause is the source error of the error the details were attached to
+	Cause error
+}
+
+// Error describes the details along with their cause
+func (d *Details) Error() string {
+	var parts []string
+	if d.Cause != nil {
+		parts = append(parts, d.Cause.Error())
+	}
+	for _, v := range d.Violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+	if d.RetryDelay > 0 {
+		parts = append(parts, "retry after "+d.RetryDelay.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Unwrap returns the cause
+func (d *Details) Unwrap() error {
+	return d.Cause
+}
+
+// Redacted returns a copy of the details to be sent to a client, the descriptions of the violations and the values
+// of the metadata are redacted by the policy, the values of the sensitive keys being masked
+func (d *Details) Redacted(p *redact.Policy) *Details {
+	redacted := *d
+	redacted.Violations = make([]Violation, len(d.Violations))
+	for i, v := range d.Violations {
+		redacted.Violations[i] = Violation{Field: v.Field, Description: p.String(v.Description)}
+	}
+	if d.Metadata != nil {
+		redacted.Metadata = make(map[string]string, len(d.Metadata))
+		for key, value := range d.Metadata {
+			redacted.Metadata[key], _ = p.Value(key, value).(string)
+		}
+	}
+	return &redacted
+}
+
+// WithViolations adds the invalid fields to the details of the error
+func WithViolations(err *errors.Error, violations ...Violation) *errors.Error {
+	d := attach(err)
+	d.Violations = append(d.Violations, violations...)
+	return err
+}
+
+// InvalidField returns a BadRequest error of the code, described by the description, with the field as its invalid field
+func InvalidField(code string, field string, description string) *errors.Error {
+	return WithViolations(errors.NewBadRequest(description).SetCode(code), Violation{Field: field, Description: description})
+}
+
+// WithRetry sets the delay after which the failed request can be retried in the details of the error
+func WithRetry(err *errors.Error, delay time.Duration) *errors.Error {
+	attach(err).RetryDelay = delay
+	return err
+}
+
+// Get returns the details of the error, nil if it has none
+func Get(err error) *Details {
+	if err == nil {
+		return nil
+	}
+	d, _ := errors.Get(err).Source.Error.(*Details)
+	return d
+}
+
+// attach returns the details of the error, they are attached to it if it has none
+func attach(err *errors.Error) *Details {
+	if d, ok := err.Source.Error.(*Details); ok {
+		return d
+	}
+	d := &Details{Cause: err.Source.Error}
+	err.Wrap(d)
+	return d
+}
@@ -0,0 +1,80 @@
+package errdetails
+
+import (
+	ierror "errors"
+	"os"
+	"testing"
+	"time"
+
+	"go-boilerplate-api/pkg/utils/redact"
+
+	"github.com/ralstan-vaz/go-errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+func TestWithViolations(t *testing.T) {
+	err := errors.NewBadRequest("The name is required").SetCode("NAME_REQUIRED")
+	assert.Nil(t, Get(err))
+
+	WithViolations(err, Violation{Field: "name", Description: "required"})
+	WithViolations(err, Violation{Field: "id", Description: "required"})
+
+	// The violations are added to the same details
+	d := Get(err)
+	if assert.NotNil(t, d) {
+		assert.Equal(t, []Violation{{Field: "name", Description: "required"}, {Field: "id", Description: "required"}}, d.Violations)
+	}
+	assert.True(t, errors.IsBadRequest(err))
+}
+
+func TestInvalidField(t *testing.T) {
+	err := InvalidField("NAME_REQUIRED", "name", "The name is required")
+	assert.True(t, errors.IsBadRequest(err))
+	assert.Equal(t, "NAME_REQUIRED", err.Code)
+	assert.Equal(t, "The name is required", err.Description)
+	if d := Get(err); assert.NotNil(t, d) {
+		assert.Equal(t, []Violation{{Field: "name", Description: "The name is required"}}, d.Violations)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	cause := ierror.New("connection refused")
+	err := WithRetry(errors.NewInternalError(cause), time.Second)
+
+	d := Get(err)
+	if assert.NotNil(t, d) {
+		assert.Equal(t, time.Second, d.RetryDelay)
+		// The source error of the error is kept as the cause of the details
+		assert.True(t, ierror.Is(d, cause))
+		assert.Equal(t, "connection refused, retry after 1s", d.Error())
+	}
+
+	assert.Nil(t, Get(nil))
+	assert.Nil(t, Get(cause))
+}
+
+func TestRedacted(t *testing.T) {
+	policy, err := redact.NewPolicy([]string{"token"}, []string{`\d{4}-\d{4}-\d{4}-\d{4}`})
+	assert.Nil(t, err)
+
+	d := &Details{
+		Violations: []Violation{{Field: "card", Description: "The card 4111-1111-1111-1111 is invalid"}},
+		Metadata:   map[string]string{"token": "abc", "card": "4111-1111-1111-1111", "service": "ratings"},
+		RetryDelay: time.Second,
+	}
+	redacted := d.Redacted(policy)
+
+	assert.Equal(t, []Violation{{Field: "card", Description: "The card " + redact.Mask + " is invalid"}}, redacted.Violations)
+	assert.Equal(t, map[string]string{"token": redact.Mask, "card": redact.Mask, "service": "ratings"}, redacted.Metadata)
+	assert.Equal(t, time.Second, redacted.RetryDelay)
+
+	// The details of the error are left as they are
+	assert.Equal(t, "The card 4111-1111-1111-1111 is invalid", d.Violations[0].Description)
+	assert.Equal(t, "abc", d.Metadata["token"])
+}
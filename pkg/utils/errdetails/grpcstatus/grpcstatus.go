@@ -0,0 +1,123 @@
+// Package grpcstatus converts the errors to google.rpc.Status and back, along with their details.
+// The code of the error is sent as the reason of an ErrorInfo, the invalid fields as a BadRequest
+// and the retry delay as a RetryInfo, so that the clients can act on them
+package grpcstatus
+
+import (
+	"context"
+	ierror "errors"
+	"go-boilerplate-api/pkg/utils/errdetails"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ralstan-vaz/go-errors"
+	errorsgrpc "github.com/ralstan-vaz/go-errors/grpc"
+	rpcdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Keys of the metadata of the ErrorInfo
+const (
+	KindKey    string = "kind"
+	MessageKey string = "message"
+)
+
+// kinds are the kinds of the errors converted from the codes of the statuses
+var kinds = map[codes.Code]errors.Kind{
+	codes.NotFound:         errors.NotFound,
+	codes.Unauthenticated:  errors.Unauthorized,
+	codes.PermissionDenied: errors.Forbidden,
+	codes.InvalidArgument:  errors.BadRequest,
+	codes.Unknown:          errors.Unknown,
+}
+
+// ToStatus converts the error to a status of the code of its kind, with the description as the message.
+// The retryable internal errors are sent as Unavailable and the errors of a done context, eg. the ones returned
+// as they are and got as internal errors, as Canceled or DeadlineExceeded
+func ToStatus(err *errors.Error, domain string) *status.Status {
+	code := errorsgrpc.StatusCode(err)
+	d := errdetails.Get(err)
+	if d != nil && d.RetryDelay > 0 && code == codes.Internal {
+		code = codes.Unavailable
+	}
+	switch {
+	case ierror.Is(err.Source.Error, context.Canceled):
+		code = codes.Canceled
+	case ierror.Is(err.Source.Error, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+
+	info := &rpcdetails.ErrorInfo{
+		Reason:   err.Code,
+		Domain:   domain,
+		Metadata: map[string]string{KindKey: string(err.Kind), MessageKey: err.Message},
+	}
+	details := []proto.Message{info}
+
+	if d != nil {
+		for key, value := range d.Metadata {
+			if _, ok := info.Metadata[key]; !ok {
+				info.Metadata[key] = value
+			}
+		}
+		if len(d.Violations) > 0 {
+			badRequest := &rpcdetails.BadRequest{}
+			for _, v := range d.Violations {
+				badRequest.FieldViolations = append(badRequest.FieldViolations, &rpcdetails.BadRequest_FieldViolation{Field: v.Field, Description: v.Description})
+			}
+			details = append(details, badRequest)
+		}
+		if d.RetryDelay > 0 {
+			details = append(details, &rpcdetails.RetryInfo{RetryDelay: durationpb.New(d.RetryDelay)})
+		}
+	}
+
+	st := status.New(code, err.Description)
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		// The details are only rejected for the OK code, the error is sent without them
+		return st
+	}
+	return withDetails
+}
+
+// FromError converts the error returned by a grpc call to an error of the kind of its code, nil if there is none.
+// The reason of the ErrorInfo is the code of the error, the message of the status its description,
+// and the invalid fields, the retry delay and the metadata are in its details (errdetails.Get)
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return errors.NewInternalError(err).SetCode("PKG.UTILS.GRPCSTATUS.NOT_A_STATUS")
+	}
+
+	kind, ok := kinds[st.Code()]
+	if !ok {
+		kind = errors.InternalError
+	}
+	e := errors.Error{Kind: kind, Description: st.Message()}
+
+	d := &errdetails.Details{Cause: err}
+	for _, detail := range st.Details() {
+		switch t := detail.(type) {
+		case *rpcdetails.ErrorInfo:
+			e.Code = t.GetReason()
+			d.Metadata = t.GetMetadata()
+			if k := t.GetMetadata()[KindKey]; k != "" {
+				e.Kind = errors.Kind(k)
+			}
+			e.Message = t.GetMetadata()[MessageKey]
+		case *rpcdetails.BadRequest:
+			for _, v := range t.GetFieldViolations() {
+				d.Violations = append(d.Violations, errdetails.Violation{Field: v.GetField(), Description: v.GetDescription()})
+			}
+		case *rpcdetails.RetryInfo:
+			d.RetryDelay = t.GetRetryDelay().AsDuration()
+		}
+	}
+
+	return errors.New(e).Wrap(d)
+}
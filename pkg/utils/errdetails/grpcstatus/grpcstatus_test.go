@@ -0,0 +1,80 @@
+package grpcstatus
+
+import (
+	"context"
+	ierror "errors"
+	"go-boilerplate-api/pkg/utils/errdetails"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ralstan-vaz/go-errors"
+	"github.com/stretchr/testify/assert"
+	rpcdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+func TestToStatus(t *testing.T) {
+	err := errors.New(errors.Error{Kind: errors.BadRequest, Code: "NAME_REQUIRED", Description: "The name is required", Message: "Enter a name"})
+	errdetails.WithViolations(err, errdetails.Violation{Field: "name", Description: "required"})
+
+	st := ToStatus(err, "test")
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "The name is required", st.Message())
+
+	details := st.Details()
+	if assert.Len(t, details, 2) {
+		info := details[0].(*rpcdetails.ErrorInfo)
+		assert.Equal(t, "NAME_REQUIRED", info.GetReason())
+		assert.Equal(t, "test", info.GetDomain())
+		assert.Equal(t, map[string]string{KindKey: string(errors.BadRequest), MessageKey: "Enter a name"}, info.GetMetadata())
+
+		badRequest := details[1].(*rpcdetails.BadRequest)
+		assert.Equal(t, "name", badRequest.GetFieldViolations()[0].GetField())
+	}
+
+	// The retryable internal errors are unavailable
+	st = ToStatus(errdetails.WithRetry(errors.NewInternalError(ierror.New("timeout")), time.Second), "test")
+	assert.Equal(t, codes.Unavailable, st.Code())
+	assert.Equal(t, time.Second, st.Details()[1].(*rpcdetails.RetryInfo).GetRetryDelay().AsDuration())
+
+	// The errors of a done context keep their cause, even with details attached
+	assert.Equal(t, codes.Canceled, ToStatus(errors.Get(context.Canceled), "test").Code())
+	assert.Equal(t, codes.DeadlineExceeded, ToStatus(errors.Get(context.DeadlineExceeded), "test").Code())
+	withDetails := errdetails.WithViolations(errors.NewInternalError(context.Canceled), errdetails.Violation{Field: "id", Description: "required"})
+	assert.Equal(t, codes.Canceled, ToStatus(withDetails, "test").Code())
+}
+
+func TestFromError(t *testing.T) {
+	err := errors.NewNotFound("User 4 does not exist").SetCode("USER_NOT_FOUND")
+	errdetails.WithRetry(err, 2*time.Second)
+
+	// The error converted back has the kind, the code and the details of the error sent
+	received := FromError(ToStatus(err, "test").Err())
+	assert.True(t, errors.IsNotFound(received))
+	assert.Equal(t, "USER_NOT_FOUND", errors.Get(received).Code)
+	assert.Equal(t, "User 4 does not exist", errors.Get(received).Description)
+	d := errdetails.Get(received)
+	if assert.NotNil(t, d) {
+		assert.Equal(t, 2*time.Second, d.RetryDelay)
+		assert.Equal(t, string(errors.NotFound), d.Metadata[KindKey])
+	}
+
+	// A status without details is converted by its code
+	received = FromError(status.Error(codes.PermissionDenied, "denied"))
+	assert.True(t, errors.IsForbidden(received))
+	assert.Equal(t, "denied", errors.Get(received).Description)
+
+	received = FromError(status.Error(codes.Unavailable, "down"))
+	assert.True(t, errors.IsInternalError(received))
+
+	assert.Equal(t, "PKG.UTILS.GRPCSTATUS.NOT_A_STATUS", errors.Get(FromError(ierror.New("failed"))).Code)
+	assert.Nil(t, FromError(nil))
+}
@@ -0,0 +1,64 @@
+// Package identity carries the identity of the client authenticated with its TLS certificate (mTLS) through the context.
+package identity
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+)
+
+// Identity is the identity of a client, read from its verified certificate
+type Identity struct {
+	CommonName   string   `json:"commonName"`
+	Organization []string `json:"organization,omitempty"`
+	DNSNames     []string `json:"dnsNames,omitempty"`
+	// URIs are the URI SANs eg. the SPIFFE ID of a workload
+	URIs         []string `json:"uris,omitempty"`
+	SerialNumber string   `json:"serialNumber"`
+	// Fingerprint is the hex encoded SHA-256 of the certificate
+	Fingerprint string `json:"fingerprint"`
+}
+
+// contextKey is the type of the key the identity is stored with in the context
+type contextKey struct{}
+
+// FromCertificate returns the identity of the certificate
+func FromCertificate(cert *x509.Certificate) *Identity {
+	fingerprint := sha256.Sum256(cert.Raw)
+	id := &Identity{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		SerialNumber: cert.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+	}
+	for _, uri := range cert.URIs {
+		id.URIs = append(id.URIs, uri.String())
+	}
+	return id
+}
+
+// FromConnectionState returns the identity of the client certificate of the connection, nil if the client sent none.
+// The servers only accept the client certificates they verified, so the first peer certificate is the verified one
+func FromConnectionState(state *tls.ConnectionState) *Identity {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	return FromCertificate(state.PeerCertificates[0])
+}
+
+// NewContext returns a copy of the context carrying the identity
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the identity of the client of the context, nil if the client was not authenticated with a certificate
+func FromContext(ctx context.Context) *Identity {
+	if ctx == nil {
+		return nil
+	}
+	id, _ := ctx.Value(contextKey{}).(*Identity)
+	return id
+}
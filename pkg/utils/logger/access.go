@@ -0,0 +1,56 @@
+package log
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// AccessOptions decide which requests are written to the access log
+type AccessOptions struct {
+	Enabled bool
+	// SuccessSampleRate is the fraction of the successful requests that are written, the failed requests are always written
+	SuccessSampleRate float64
+	// Exclude are the http paths or route templates and the grpc full methods that are never written
+	Exclude []string
+}
+
+// Logs reports if the access log of a request is written.
+// The names identify the request eg. its path and its route template, the request is excluded if any of them is.
+// A trailing slash is ignored so that /ping excludes /ping/ as well
+func (o AccessOptions) Logs(failed bool, names ...string) bool {
+	if !o.Enabled {
+		return false
+	}
+
+	for _, excluded := range o.Exclude {
+		excluded = strings.TrimSuffix(excluded, "/")
+		for _, name := range names {
+			if name != "" && strings.TrimSuffix(name, "/") == excluded {
+				return false
+			}
+		}
+	}
+
+	if failed || o.SuccessSampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < o.SuccessSampleRate
+}
+
+// Access writes the access log of a request with the fields, as a warning if the request failed and as info otherwise.
+// The access options already decide which requests are written, so the failed ones are written whatever the log level
+// eg. log.level error does not hide the failed requests. The successful ones are filtered by the level like any info log
+func (e *Entry) Access(failed bool, description string, fields Fields) {
+	if Logger == nil {
+		return
+	}
+
+	if failed {
+		Logger.Warn(redactDescription(description), e.reference([]interface{}{fields})...)
+		return
+	}
+	if !enabled(LevelInfo) {
+		return
+	}
+	Logger.Info(redactDescription(description), e.reference([]interface{}{fields})...)
+}
@@ -0,0 +1,146 @@
+package log
+
+import (
+	"context"
+	"go-boilerplate-api/pkg/utils/requestid"
+)
+
+// Fields are the key value pairs added to every log of an Entry eg. the user ID
+type Fields map[string]interface{}
+
+// Entry is a logger scoped with fields, the fields are added to the reference of every log it writes.
+// An Entry is never modified, WithFields returns a new one so that it can be shared between goroutines
+type Entry struct {
+	fields Fields
+}
+
+// contextKey is the type of the key the Entry is stored with in the context
+type contextKey struct{}
+
+// WithFields returns a logger scoped with the fields
+func WithFields(fields Fields) *Entry {
+	return (&Entry{}).WithFields(fields)
+}
+
+// WithField returns a logger scoped with the field
+func WithField(key string, value interface{}) *Entry {
+	return WithFields(Fields{key: value})
+}
+
+// NewContext returns a copy of the context carrying the logger,
+// FromContext returns it to the functions the context is passed to
+func NewContext(ctx context.Context, entry *Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry)
+}
+
+// FromContext returns the logger of the context scoped with the request ID of the context, if any.
+// A logger without fields is returned if the context carries none
+func FromContext(ctx context.Context) *Entry {
+	if ctx == nil {
+		return &Entry{}
+	}
+
+	entry, ok := ctx.Value(contextKey{}).(*Entry)
+	if !ok {
+		entry = &Entry{}
+	}
+
+	id := requestid.FromContext(ctx)
+	if _, exists := entry.fields["requestId"]; id == "" || exists {
+		return entry
+	}
+	return entry.WithField("requestId", id)
+}
+
+// WithFields returns a copy of the logger scoped with the fields as well, a field that exists is replaced
+func (e *Entry) WithFields(fields Fields) *Entry {
+	merged := make(Fields, len(e.fields)+len(fields))
+	for key, value := range e.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return &Entry{fields: merged}
+}
+
+// WithField returns a copy of the logger scoped with the field as well
+func (e *Entry) WithField(key string, value interface{}) *Entry {
+	return e.WithFields(Fields{key: value})
+}
+
+// The methods call the logger directly rather than the global functions
+// so that the caller is reported the same way as with the global functions
+
+// Info ...
+func (e *Entry) Info(description string, reference ...interface{}) {
+	if Logger == nil || !enabled(LevelInfo) {
+		return
+	}
+
+	Logger.Info(redactDescription(description), e.reference(reference)...)
+}
+
+// Debug ...
+func (e *Entry) Debug(description string, reference ...interface{}) {
+	if Logger == nil || !enabled(LevelDebug) {
+		return
+	}
+
+	Logger.Debug(redactDescription(description), e.reference(reference)...)
+}
+
+// Warn ...
+func (e *Entry) Warn(description string, reference ...interface{}) {
+	if Logger == nil || !enabled(LevelWarn) {
+		return
+	}
+
+	Logger.Warn(redactDescription(description), e.reference(reference)...)
+}
+
+// Error ...
+func (e *Entry) Error(code string, description string, severity string, source interface{}, reference ...interface{}) {
+	if Logger == nil {
+		return
+	}
+
+	Logger.Error(code, redactDescription(description), severity, mapSource(source), e.reference(reference)...)
+}
+
+// Fatal ...
+func (e *Entry) Fatal(code string, description string, source interface{}, reference ...interface{}) {
+	if Logger == nil {
+		return
+	}
+
+	Logger.Fatal(code, redactDescription(description), mapSource(source), e.reference(reference)...)
+}
+
+// reference adds the fields of the logger to the reference of a log and redacts it.
+// The logger only prints the first reference, so a map reference is merged and any other value is nested under "reference"
+func (e *Entry) reference(reference []interface{}) []interface{} {
+	if len(e.fields) == 0 {
+		return redactReference(reference)
+	}
+
+	merged := make(map[string]interface{}, len(e.fields)+1)
+	for key, value := range e.fields {
+		merged[key] = value
+	}
+	if len(reference) > 0 && reference[0] != nil {
+		switch ref := reference[0].(type) {
+		case map[string]interface{}:
+			for key, value := range ref {
+				merged[key] = value
+			}
+		case Fields:
+			for key, value := range ref {
+				merged[key] = value
+			}
+		default:
+			merged["reference"] = ref
+		}
+	}
+	return redactReference([]interface{}{merged})
+}
@@ -0,0 +1,238 @@
+package log
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// Level is the severity of a log, the logs below the level in effect are discarded
+type Level int
+
+const (
+	// LevelDebug ...
+	LevelDebug Level = iota
+	// LevelInfo ...
+	LevelInfo
+	// LevelWarn ...
+	LevelWarn
+	// LevelError ...
+	LevelError
+
+	// modulePrefix is trimmed from the package of the caller so that the packages are configured by their path within the module
+	modulePrefix = "go-boilerplate-api/"
+)
+
+// levelNames are the names of the levels in the config and the admin endpoint
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// ParseLevel returns the level of the name eg. debug
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if levelName == name {
+			return level, nil
+		}
+	}
+	return LevelDebug, errors.NewBadRequest("Invalid log level " + name + ", must be one of debug info warn error").SetCode("LOG.INVALID_LEVEL")
+}
+
+// String returns the name of the level
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// Levels are the log levels of the app and of the packages that override it
+type Levels struct {
+	Level string `json:"level"`
+	// Packages are keyed by their path within the module eg. pkg/user/rating,
+	// the level of a package also applies to the packages under it
+	Packages map[string]string `json:"packages,omitempty"`
+	// ExpiresAt is when the levels changed at runtime revert to the configured ones, nil for the configured levels
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// levels are the parsed Levels
+type levels struct {
+	level     Level
+	packages  map[string]Level
+	expiresAt *time.Time
+}
+
+var (
+	// current holds the *levels in effect, it is read on every log
+	current atomic.Value
+
+	// levelsMu guards the configured and the overridden levels
+	levelsMu sync.Mutex
+	// configured are the levels of the config, in effect when there is no override
+	configured = &levels{level: LevelDebug}
+	// override are the levels changed at runtime, nil once they are reverted
+	override      *levels
+	overrideTimer *time.Timer
+)
+
+func init() {
+	current.Store(configured)
+}
+
+// SetLevels sets the configured levels, they take effect once the levels changed at runtime, if any, are reverted
+func SetLevels(l Levels) error {
+	parsed, err := parseLevels(l)
+	if err != nil {
+		return err
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	configured = parsed
+	if override == nil {
+		current.Store(configured)
+	}
+	return nil
+}
+
+// OverrideLevels changes the levels at runtime, they revert to the configured levels once the TTL expires.
+// A new override replaces the previous one along with its TTL
+func OverrideLevels(l Levels, ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.NewBadRequest("The ttl of the log levels must be greater than 0").SetCode("LOG.INVALID_TTL")
+	}
+
+	parsed, err := parseLevels(l)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(ttl)
+	parsed.expiresAt = &expiresAt
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	if overrideTimer != nil {
+		overrideTimer.Stop()
+	}
+	override = parsed
+	current.Store(override)
+
+	// The timer only reverts the override it was started for, a newer one is left in effect
+	overrideTimer = time.AfterFunc(ttl, func() {
+		levelsMu.Lock()
+		defer levelsMu.Unlock()
+		if override == parsed {
+			revertLevels()
+		}
+	})
+	return nil
+}
+
+// RevertLevels reverts the levels changed at runtime to the configured levels
+func RevertLevels() {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	revertLevels()
+}
+
+// revertLevels reverts the override, the caller must hold levelsMu
+func revertLevels() {
+	if overrideTimer != nil {
+		overrideTimer.Stop()
+		overrideTimer = nil
+	}
+	override = nil
+	current.Store(configured)
+}
+
+// CurrentLevels returns the levels in effect
+func CurrentLevels() Levels {
+	l := current.Load().(*levels)
+	result := Levels{Level: l.level.String(), ExpiresAt: l.expiresAt}
+	if len(l.packages) > 0 {
+		result.Packages = make(map[string]string, len(l.packages))
+		for pkg, level := range l.packages {
+			result.Packages[pkg] = level.String()
+		}
+	}
+	return result
+}
+
+// parseLevels parses the names of the levels, an empty level is info
+func parseLevels(l Levels) (*levels, error) {
+	parsed := &levels{level: LevelInfo}
+	if l.Level != "" {
+		level, err := ParseLevel(l.Level)
+		if err != nil {
+			return nil, err
+		}
+		parsed.level = level
+	}
+
+	if len(l.Packages) > 0 {
+		parsed.packages = make(map[string]Level, len(l.Packages))
+		for pkg, name := range l.Packages {
+			level, err := ParseLevel(name)
+			if err != nil {
+				return nil, err
+			}
+			parsed.packages[strings.Trim(strings.TrimPrefix(pkg, modulePrefix), "/")] = level
+		}
+	}
+	return parsed, nil
+}
+
+// enabled reports if a log of the level is written.
+// It must be called directly by the logging functions, the package of their caller is looked up only when packages override the level
+func enabled(level Level) bool {
+	l := current.Load().(*levels)
+	if level >= LevelError {
+		return true
+	}
+	if len(l.packages) == 0 {
+		return level >= l.level
+	}
+	// 0 is callerPackage, 1 is enabled, 2 is the logging function and 3 is its caller
+	return level >= l.levelOf(callerPackage(3))
+}
+
+// levelOf returns the level of the package, the closest parent package that overrides the level applies
+func (l *levels) levelOf(pkg string) Level {
+	level, matched := l.level, ""
+	for overridden, overriddenLevel := range l.packages {
+		if pkg != overridden && !strings.HasPrefix(pkg, overridden+"/") {
+			continue
+		}
+		if len(overridden) > len(matched) {
+			level, matched = overriddenLevel, overridden
+		}
+	}
+	return level
+}
+
+// callerPackage returns the path within the module of the package of the function skip frames up the stack
+// eg. pkg/user/rating for go-boilerplate-api/pkg/user/rating.(*Rating).Get
+func callerPackage(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return ""
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+
+	name := fn.Name()
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		name = name[:slash+1+dot]
+	}
+	return strings.TrimPrefix(name, modulePrefix)
+}
@@ -0,0 +1,68 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// logs reports if a log of the level is written by the caller, the way the logging functions do
+func logs(level Level) bool {
+	return enabled(level)
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("warn")
+	assert.Nil(t, err)
+	assert.Equal(t, LevelWarn, level)
+
+	_, err = ParseLevel("verbose")
+	assert.NotNil(t, err)
+}
+
+func TestLevelOf(t *testing.T) {
+	l, err := parseLevels(Levels{Level: "warn", Packages: map[string]string{
+		"pkg/user":                           "info",
+		"go-boilerplate-api/pkg/user/rating": "debug",
+	}})
+	assert.Nil(t, err)
+
+	assert.Equal(t, LevelDebug, l.levelOf("pkg/user/rating"))
+	assert.Equal(t, LevelInfo, l.levelOf("pkg/user/favourite"))
+	assert.Equal(t, LevelInfo, l.levelOf("pkg/user"))
+	assert.Equal(t, LevelWarn, l.levelOf("pkg/username"))
+	assert.Equal(t, LevelWarn, l.levelOf("apis/http"))
+}
+
+func TestCallerPackage(t *testing.T) {
+	assert.Equal(t, "pkg/utils/logger", callerPackage(1))
+}
+
+func TestSetAndOverrideLevels(t *testing.T) {
+	defer SetLevels(Levels{Level: "debug"})
+
+	assert.Nil(t, SetLevels(Levels{Level: "warn", Packages: map[string]string{"pkg/utils/logger": "info"}}))
+	assert.True(t, logs(LevelInfo))
+	assert.False(t, logs(LevelDebug))
+	assert.True(t, logs(LevelError))
+
+	assert.Nil(t, OverrideLevels(Levels{Level: "debug"}, 50*time.Millisecond))
+	assert.True(t, logs(LevelDebug))
+	assert.Equal(t, "debug", CurrentLevels().Level)
+	assert.NotNil(t, CurrentLevels().ExpiresAt)
+
+	// The configured levels are set aside until the override expires
+	assert.Nil(t, SetLevels(Levels{Level: "error"}))
+	assert.True(t, logs(LevelDebug))
+
+	assert.Eventually(t, func() bool { return !logs(LevelWarn) }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, Levels{Level: "error"}, CurrentLevels())
+
+	assert.Nil(t, OverrideLevels(Levels{Level: "info"}, time.Minute))
+	RevertLevels()
+	assert.Equal(t, Levels{Level: "error"}, CurrentLevels())
+
+	assert.NotNil(t, OverrideLevels(Levels{Level: "info"}, 0))
+	assert.NotNil(t, SetLevels(Levels{Packages: map[string]string{"pkg/user": "loud"}}))
+}
@@ -1,6 +1,8 @@
 package log
 
 import (
+	"go-boilerplate-api/pkg/utils/redact"
+
 	"github.com/ralstan-vaz/go-errors"
 	"stash.bms.bz/merchandise/go-logger"
 )
@@ -23,7 +25,7 @@ func InitLogger() {
 	newLogger := logger.New("go-boilerplate", 2)
 	// makes logger a global singleton
 	logger.Global(newLogger)
-	// enables debug logs
+	// enables debug logs, the logs are filtered by the levels in effect, see SetLevels
 	logger.GLog.Set(`{"debug":true,"reference":"string"}`)
 
 	Logger = logger.GLog
@@ -31,29 +33,29 @@ func InitLogger() {
 
 // Info ...
 func Info(description string, reference ...interface{}) {
-	if Logger == nil {
+	if Logger == nil || !enabled(LevelInfo) {
 		return
 	}
 
-	Logger.Info(description, reference...)
+	Logger.Info(redactDescription(description), redactReference(reference)...)
 }
 
 // Debug ...
 func Debug(description string, reference ...interface{}) {
-	if Logger == nil {
+	if Logger == nil || !enabled(LevelDebug) {
 		return
 	}
 
-	Logger.Debug(description, reference...)
+	Logger.Debug(redactDescription(description), redactReference(reference)...)
 }
 
 // Warn ...
 func Warn(description string, reference ...interface{}) {
-	if Logger == nil {
+	if Logger == nil || !enabled(LevelWarn) {
 		return
 	}
 
-	Logger.Warn(description, reference...)
+	Logger.Warn(redactDescription(description), redactReference(reference)...)
 }
 
 // Error ...
@@ -62,7 +64,7 @@ func Error(code string, description string, severity string, source interface{},
 		return
 	}
 
-	Logger.Error(code, description, severity, mapSource(source), reference...)
+	Logger.Error(code, redactDescription(description), severity, mapSource(source), redactReference(reference)...)
 }
 
 // Fatal ...
@@ -71,7 +73,7 @@ func Fatal(code string, description string, source interface{}, reference ...int
 		return
 	}
 
-	Logger.Fatal(code, description, mapSource(source), reference...)
+	Logger.Fatal(code, redactDescription(description), mapSource(source), redactReference(reference)...)
 }
 
 // used to map a different error source to the logger error source struct
@@ -89,10 +91,23 @@ func mapSource(source interface{}) *logger.ErrorSource {
 		errSource.StackTrace = &newSource.StackTrace
 		if newSource.Error != nil {
 			errSource.ErrorSource = newSource.Error
-			errStr := newSource.Error.Error()
+			errStr := redactDescription(newSource.Error.Error())
 			errSource.ErrorString = &errStr
 		}
 	}
 
 	return errSource
 }
+
+// redactDescription redacts the parts of the description that match the patterns of the redaction policy
+func redactDescription(description string) string {
+	return redact.Current().String(description)
+}
+
+// redactReference redacts the reference of a log with the redaction policy, the logger only prints the first reference
+func redactReference(reference []interface{}) []interface{} {
+	if len(reference) == 0 || reference[0] == nil {
+		return reference
+	}
+	return []interface{}{redact.Current().Redact(reference[0])}
+}
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"context"
+	"go-boilerplate-api/pkg/utils/redact"
+	"go-boilerplate-api/pkg/utils/requestid"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+func TestReference(t *testing.T) {
+	ref := []interface{}{map[string]interface{}{"error": "failed"}}
+
+	// Without fields the reference is left as it is
+	assert.Equal(t, ref, (&Entry{}).reference(ref))
+
+	entry := WithField("userId", "1")
+	assert.Equal(t, []interface{}{map[string]interface{}{"userId": "1", "error": "failed"}}, entry.reference(ref))
+	assert.Equal(t, []interface{}{map[string]interface{}{"userId": "1", "reference": "value"}}, entry.reference([]interface{}{"value"}))
+	assert.Equal(t, []interface{}{map[string]interface{}{"userId": "1"}}, entry.reference(nil))
+
+	// The map of the caller is not modified
+	assert.Equal(t, map[string]interface{}{"error": "failed"}, ref[0])
+}
+
+func TestWithFields(t *testing.T) {
+	parent := WithFields(Fields{"userId": "1", "traceId": "a"})
+	child := parent.WithField("traceId", "b")
+
+	assert.Equal(t, Fields{"userId": "1", "traceId": "b"}, child.fields)
+	// The parent is not modified
+	assert.Equal(t, Fields{"userId": "1", "traceId": "a"}, parent.fields)
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()).fields)
+
+	ctx := requestid.NewContext(context.Background(), "abc")
+	assert.Equal(t, Fields{"requestId": "abc"}, FromContext(ctx).fields)
+
+	ctx = NewContext(ctx, FromContext(ctx).WithField("userId", "1"))
+	assert.Equal(t, Fields{"requestId": "abc", "userId": "1"}, FromContext(ctx).fields)
+}
+
+func TestReferenceRedacted(t *testing.T) {
+	entry := WithField("userId", "1")
+
+	// The default redaction policy masks the passwords
+	assert.Equal(t, []interface{}{map[string]interface{}{"userId": "1", "password": redact.Mask}}, entry.reference([]interface{}{map[string]interface{}{"password": "hunter2"}}))
+	assert.Equal(t, []interface{}{map[string]interface{}{"token": redact.Mask}}, redactReference([]interface{}{map[string]interface{}{"token": "abc"}}))
+}
+
+func TestAccessOptions(t *testing.T) {
+	opts := AccessOptions{Enabled: true, SuccessSampleRate: 0, Exclude: []string{"/ping", "/metrics/"}}
+
+	// The successful requests are not sampled, the failed ones are always written
+	assert.False(t, opts.Logs(false, "/users/1", "/users/:userId"))
+	assert.True(t, opts.Logs(true, "/users/1", "/users/:userId"))
+
+	// The excluded requests are never written
+	assert.False(t, opts.Logs(true, "/ping/", "/ping/"))
+	assert.False(t, opts.Logs(true, "/metrics"))
+
+	opts.SuccessSampleRate = 1
+	assert.True(t, opts.Logs(false, "/users/1", "/users/:userId"))
+
+	opts.Enabled = false
+	assert.False(t, opts.Logs(true, "/users/1"))
+}
@@ -0,0 +1,54 @@
+// Package mergepatch applies JSON Merge Patches (RFC 7386) to JSON documents.
+// The members of a patch replace the ones of the document, the null members remove them and the nested objects are merged
+package mergepatch
+
+import (
+	"encoding/json"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// ContentType is the media type of a JSON Merge Patch
+const ContentType string = "application/merge-patch+json"
+
+// Apply applies the patch to the document and returns the patched document
+func Apply(doc []byte, patch []byte) ([]byte, error) {
+	var target interface{}
+	err := json.Unmarshal(doc, &target)
+	if err != nil {
+		return nil, errors.NewBadRequest("Invalid JSON document : " + err.Error()).SetCode("PKG.UTILS.MERGEPATCH.INVALID_DOCUMENT")
+	}
+
+	var p interface{}
+	err = json.Unmarshal(patch, &p)
+	if err != nil {
+		return nil, errors.NewBadRequest("Invalid JSON merge patch : " + err.Error()).SetCode("PKG.UTILS.MERGEPATCH.INVALID_PATCH")
+	}
+
+	patched, err := json.Marshal(merge(target, p))
+	if err != nil {
+		return nil, errors.NewInternalError(err).SetCode("PKG.UTILS.MERGEPATCH.ENCODE_FAILED")
+	}
+	return patched, nil
+}
+
+// merge merges the patch into the target, a patch that is not an object replaces the target as a whole
+func merge(target interface{}, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = merge(targetObj[key], value)
+	}
+	return targetObj
+}
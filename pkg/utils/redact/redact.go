@@ -0,0 +1,172 @@
+package redact
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// Mask replaces the redacted values
+const Mask string = "****"
+
+// DefaultFields are the sensitive fields redacted until the policy of the config is set
+var DefaultFields = []string{"password", "secret", "token", "accessToken", "refreshToken", "apiKey", "authorization", "cookie"}
+
+// Policy redacts the values of the sensitive fields and the parts of the strings that match the sensitive patterns.
+// It is applied to the log references, the apm attributes, the query strings and the panic responses
+type Policy struct {
+	fields   map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// current holds the *Policy in effect
+var current atomic.Value
+
+func init() {
+	policy, _ := NewPolicy(DefaultFields, nil)
+	current.Store(policy)
+}
+
+// NewPolicy creates a policy redacting the fields and the patterns.
+// The fields are matched ignoring the case, - and _ eg. apiKey matches api_key and Api-Key
+func NewPolicy(fields []string, patterns []string) (*Policy, error) {
+	p := &Policy{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		p.fields[normalize(field)] = true
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.NewBadRequest("Invalid redaction pattern " + pattern + " : " + err.Error()).SetCode("REDACT.INVALID_PATTERN")
+		}
+		p.patterns = append(p.patterns, re)
+	}
+	return p, nil
+}
+
+// SetPolicy sets the policy in effect
+func SetPolicy(p *Policy) {
+	current.Store(p)
+}
+
+// Current returns the policy in effect
+func Current() *Policy {
+	return current.Load().(*Policy)
+}
+
+// normalize lowers the case of the field and removes - and _
+func normalize(field string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(field))
+}
+
+// Sensitive reports if the field is sensitive
+func (p *Policy) Sensitive(field string) bool {
+	return p.fields[normalize(field)]
+}
+
+// String redacts the parts of the string that match the patterns
+func (p *Policy) String(s string) string {
+	for _, re := range p.patterns {
+		s = re.ReplaceAllString(s, Mask)
+	}
+	return s
+}
+
+// Value redacts the value of the field, masked if the field is sensitive.
+// The strings are redacted by the patterns and the maps, the slices and the structs field by field
+func (p *Policy) Value(field string, value interface{}) interface{} {
+	if p.Sensitive(field) {
+		return Mask
+	}
+	return p.Redact(value)
+}
+
+// Map returns a copy of the map in which the values are redacted
+func (p *Policy) Map(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		redacted[key] = p.Value(key, value)
+	}
+	return redacted
+}
+
+// Query redacts the raw query string, the sensitive params are masked and the other values are redacted by the patterns
+func (p *Policy) Query(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// A query that cannot be parsed is not sent as it is
+		return p.String(rawQuery)
+	}
+	for key, vals := range values {
+		for i := range vals {
+			if p.Sensitive(key) {
+				vals[i] = Mask
+			} else {
+				vals[i] = p.String(vals[i])
+			}
+		}
+	}
+	return values.Encode()
+}
+
+// URL returns the URL with its query string and its password redacted
+func (p *Policy) URL(u *url.URL) string {
+	redacted := *u
+	redacted.RawQuery = p.Query(u.RawQuery)
+	if _, ok := u.User.Password(); ok {
+		redacted.User = url.UserPassword(u.User.Username(), Mask)
+	}
+	return redacted.String()
+}
+
+// Redact redacts the value, the values that are not strings, maps or slices are converted through json to be redacted
+func (p *Policy) Redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, int, int32, int64, uint, uint32, uint64, float32, float64:
+		return v
+	case string:
+		return p.String(v)
+	case map[string]interface{}:
+		return p.Map(v)
+	case map[string]string:
+		redacted := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			redacted[key] = p.Value(key, val)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, val := range v {
+			redacted[i] = p.Redact(val)
+		}
+		return redacted
+	case []string:
+		redacted := make([]interface{}, len(v))
+		for i, val := range v {
+			redacted[i] = p.String(val)
+		}
+		return redacted
+	case error:
+		return p.String(v.Error())
+	}
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return value
+	}
+	return p.Redact(decoded)
+}
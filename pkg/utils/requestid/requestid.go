@@ -0,0 +1,91 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Header is the http header carrying the request ID
+	Header string = "X-Request-ID"
+	// MetadataKey is the grpc metadata key carrying the request ID, grpc metadata keys are lowercase
+	MetadataKey string = "x-request-id"
+
+	// maxLength is the longest request ID accepted from a client
+	maxLength = 128
+)
+
+// contextKey is the type of the key the request ID is stored with in the context
+type contextKey struct{}
+
+// fallback is used to generate IDs when the random source fails
+var fallback uint64
+
+// NewContext returns a copy of the context carrying the request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID of the context, empty if there is none
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// New generates a random request ID in the UUID v4 format
+func New() string {
+	var b [16]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		// Unique within the process, which is enough to correlate the logs
+		return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(atomic.AddUint64(&fallback, 1), 36)
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf)
+}
+
+// Valid reports if a request ID sent by a client can be accepted.
+// Only short IDs made of letters, digits and -_.: are accepted so that they are safe to log and forward
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Resolve returns the request ID sent by the client if it is valid, a new one otherwise
+func Resolve(id string) string {
+	if Valid(id) {
+		return id
+	}
+	return New()
+}
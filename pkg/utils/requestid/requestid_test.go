@@ -0,0 +1,39 @@
+package requestid
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+func TestNew(t *testing.T) {
+	id := New()
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+	assert.NotEqual(t, id, New())
+}
+
+func TestResolve(t *testing.T) {
+	assert.Equal(t, "abc-123_x.y:z", Resolve("abc-123_x.y:z"))
+
+	// Invalid IDs are replaced so that they cannot be used to inject into the logs or the headers
+	for _, id := range []string{"", "with space", "new\nline", strings.Repeat("a", maxLength+1)} {
+		resolved := Resolve(id)
+		assert.NotEqual(t, id, resolved)
+		assert.True(t, Valid(resolved))
+	}
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, "", FromContext(context.Background()))
+	assert.Equal(t, "abc", FromContext(NewContext(context.Background(), "abc")))
+}
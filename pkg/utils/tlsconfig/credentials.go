@@ -0,0 +1,50 @@
+package tlsconfig
+
+import (
+	"context"
+	"net"
+
+	"github.com/ralstan-vaz/go-errors"
+	"google.golang.org/grpc/credentials"
+)
+
+// clientCredentials are the grpc transport credentials of a client,
+// the TLS config is built on every handshake so that the reloaded CAs apply to the new connections
+type clientCredentials struct {
+	reloader *Reloader
+}
+
+// ClientCredentials returns the grpc transport credentials of a client using the certificate and the CAs of the reloader
+func (r *Reloader) ClientCredentials() credentials.TransportCredentials {
+	return &clientCredentials{reloader: r}
+}
+
+// ClientHandshake does the TLS handshake with the server, the host of the authority is the server name unless the options override it
+func (c *clientCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return credentials.NewTLS(c.reloader.ClientConfig("")).ClientHandshake(ctx, authority, rawConn)
+}
+
+// ServerHandshake fails as the credentials are only meant for the clients
+func (c *clientCredentials) ServerHandshake(net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New(errors.Error{
+		Kind:        errors.InternalError,
+		Code:        "PKG.UTILS.TLS.CLIENT_CREDENTIALS_ONLY",
+		Description: "The client credentials can not be used by a server",
+	})
+}
+
+// Info describes the credentials. The deprecated SecurityVersion is left empty as the version is negotiated per connection,
+// it is read from the AuthInfo of the peer instead
+func (c *clientCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "tls", ServerName: c.reloader.opts.ServerName}
+}
+
+// Clone ...
+func (c *clientCredentials) Clone() credentials.TransportCredentials {
+	return &clientCredentials{reloader: c.reloader}
+}
+
+// OverrideServerName is not supported, the server name is overridden through the options
+func (c *clientCredentials) OverrideServerName(string) error {
+	return errors.NewBadRequest("The server name is overridden through the TLS options").SetCode("PKG.UTILS.TLS.OVERRIDE_NOT_SUPPORTED")
+}
@@ -0,0 +1,305 @@
+// Package tlsconfig builds the TLS configs of the servers and of the clients from PEM files.
+// The certificates and the CAs are reloaded when their files change, so that they can be rotated without a restart.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	log "go-boilerplate-api/pkg/utils/logger"
+
+	"github.com/ralstan-vaz/go-errors"
+)
+
+// DefaultReloadInterval is used when the files are watched without an interval
+const DefaultReloadInterval = 10 * time.Second
+
+// versions are the names of the TLS versions in the config
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Options are the files and the settings of a TLS config
+type Options struct {
+	// CertFile and KeyFile are the PEM files of the certificate and of its private key,
+	// required for a server and presented by a client to the servers requiring mTLS
+	CertFile string
+	KeyFile  string
+	// CAFile is the PEM file of the CAs verifying the peer. A server with CAs verifies the client certificates (mTLS),
+	// a client without CAs verifies the server with the CAs of the system
+	CAFile string
+	// RequireClientCert makes a server with CAs reject the clients without a certificate,
+	// the clients are only verified when they present one otherwise
+	RequireClientCert bool
+	// ServerName overrides the name a client verifies the server certificate against, the host it connects to by default
+	ServerName string
+	// MinVersion is the minimum TLS version eg. 1.3, 1.2 by default
+	MinVersion string
+}
+
+// Reloader keeps the certificate and the CAs loaded from the files of the options.
+// The files are checked for changes periodically, the certificate and the CAs are replaced once they load successfully
+type Reloader struct {
+	opts       Options
+	minVersion uint16
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	cas      *x509.CertPool
+	modTimes map[string]time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewReloader loads the files of the options and checks them for changes every interval until it is closed
+func NewReloader(opts Options, interval time.Duration) (*Reloader, error) {
+	minVersion := uint16(tls.VersionTLS12)
+	if opts.MinVersion != "" {
+		version, ok := versions[opts.MinVersion]
+		if !ok {
+			return nil, errors.NewBadRequest("Invalid TLS version " + opts.MinVersion + ", must be one of 1.2 1.3").SetCode("PKG.UTILS.TLS.INVALID_VERSION")
+		}
+		minVersion = version
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, errors.NewBadRequest("The certificate and the key files must be set together").SetCode("PKG.UTILS.TLS.INVALID_KEY_PAIR")
+	}
+
+	r := &Reloader{opts: opts, minVersion: minVersion, stop: make(chan struct{})}
+	err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	go r.watch(interval)
+
+	return r, nil
+}
+
+// Certificate returns the certificate in effect, nil if there is no certificate file
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// CAs returns the CAs in effect, nil if there is no CA file
+func (r *Reloader) CAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cas
+}
+
+// Close stops watching the files for changes
+func (r *Reloader) Close() error {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	return nil
+}
+
+// ServerConfig returns the TLS config of a server. The certificate and the CAs in effect are used on every handshake,
+// the client certificates are verified against the CAs, if any, and the clients without one are rejected if RequireClientCert is set
+func (r *Reloader) ServerConfig() (*tls.Config, error) {
+	if r.opts.CertFile == "" {
+		return nil, errors.NewBadRequest("A server needs a certificate and a key file").SetCode("PKG.UTILS.TLS.CERTIFICATE_MISSING")
+	}
+
+	conf := &tls.Config{
+		MinVersion: r.minVersion,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.Certificate(), nil
+		},
+	}
+
+	if r.opts.CAFile != "" {
+		// The chain is verified by verifyClient rather than through ClientCAs so that the CAs can be reloaded
+		conf.ClientAuth = tls.RequestClientCert
+		if r.opts.RequireClientCert {
+			conf.ClientAuth = tls.RequireAnyClientCert
+		}
+		conf.VerifyPeerCertificate = r.verifyClient
+	}
+	return conf, nil
+}
+
+// ClientConfig returns the TLS config of a client connecting to the server name, ServerName of the options overrides it.
+// It uses the CAs in effect at the time of the call, so a new config is needed per connection for the reloaded CAs to apply
+func (r *Reloader) ClientConfig(serverName string) *tls.Config {
+	if r.opts.ServerName != "" {
+		serverName = r.opts.ServerName
+	}
+
+	conf := &tls.Config{
+		MinVersion: r.minVersion,
+		ServerName: serverName,
+		RootCAs:    r.CAs(),
+	}
+	if r.opts.CertFile != "" {
+		conf.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.Certificate(), nil
+		}
+	}
+	return conf
+}
+
+// verifyClient verifies the chain of the client certificate, if any, against the CAs in effect
+func (r *Reloader) verifyClient(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return nil
+	}
+
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return errors.NewUnauthorized("Invalid client certificate : " + err.Error()).SetCode("PKG.UTILS.TLS.INVALID_CLIENT_CERTIFICATE")
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         r.CAs(),
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return errors.NewUnauthorized("Client certificate could not be verified : " + err.Error()).SetCode("PKG.UTILS.TLS.UNVERIFIED_CLIENT_CERTIFICATE")
+	}
+	return nil
+}
+
+// watch reloads the files when their modification time changes, it runs until Close is called
+func (r *Reloader) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.reloadIfChanged()
+		}
+	}
+}
+
+// reloadIfChanged reloads the files if any of them changed.
+// The current certificate and CAs are kept if the files do not load eg. when the key is not yet replaced along with the certificate
+func (r *Reloader) reloadIfChanged() {
+	modTimes, err := r.filesModTime()
+	if err != nil {
+		newErr := errors.Get(err)
+		log.Error(newErr.Code, "TLS files could not be checked for changes : "+newErr.Description, log.Priority2, newErr.Source)
+		return
+	}
+
+	r.mu.RLock()
+	changed := !modTimesEqual(modTimes, r.modTimes)
+	r.mu.RUnlock()
+	if !changed {
+		return
+	}
+
+	err = r.load()
+	if err != nil {
+		newErr := errors.Get(err)
+		log.Error(newErr.Code, "TLS files could not be reloaded, the current ones are kept : "+newErr.Description, log.Priority2, newErr.Source)
+		return
+	}
+	log.Info("TLS files reloaded", map[string]interface{}{"certFile": r.opts.CertFile, "caFile": r.opts.CAFile})
+}
+
+// load reads the certificate and the CAs and replaces the ones in effect
+func (r *Reloader) load() error {
+	// The modification times are read first so that a change made while loading is loaded again
+	modTimes, err := r.filesModTime()
+	if err != nil {
+		return err
+	}
+
+	var cert *tls.Certificate
+	if r.opts.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+		if err != nil {
+			return errors.NewInternalError(err).SetCode("PKG.UTILS.TLS.KEY_PAIR_LOAD_FAILED")
+		}
+		cert = &pair
+	}
+
+	var cas *x509.CertPool
+	if r.opts.CAFile != "" {
+		bytes, err := ioutil.ReadFile(r.opts.CAFile)
+		if err != nil {
+			return errors.NewInternalError(err).SetCode("PKG.UTILS.TLS.CA_LOAD_FAILED")
+		}
+		cas = x509.NewCertPool()
+		if !cas.AppendCertsFromPEM(bytes) {
+			return errors.NewBadRequest("No certificate could be parsed from " + r.opts.CAFile).SetCode("PKG.UTILS.TLS.INVALID_CA")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.cas, r.modTimes = cert, cas, modTimes
+	return nil
+}
+
+// filesModTime returns the modification time of the files of the options
+func (r *Reloader) filesModTime() (map[string]time.Time, error) {
+	modTimes := map[string]time.Time{}
+	for _, file := range []string{r.opts.CertFile, r.opts.KeyFile, r.opts.CAFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, errors.NewInternalError(err).SetCode("PKG.UTILS.TLS.FILE_STAT_FAILED")
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// modTimesEqual reports if the files and their modification times are the same
+func modTimesEqual(a map[string]time.Time, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for file, modTime := range a {
+		if !modTime.Equal(b[file]) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewServerConfig loads the files of the options and returns the TLS config of a server
+// along with the reloader of the files, which must be closed once the server stops
+func NewServerConfig(opts Options, interval time.Duration) (*tls.Config, *Reloader, error) {
+	r, err := NewReloader(opts, interval)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conf, err := r.ServerConfig()
+	if err != nil {
+		r.Close()
+		return nil, nil, err
+	}
+	return conf, r, nil
+}
@@ -0,0 +1,286 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"go-boilerplate-api/pkg/utils/identity"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// can be used if some prior setup is required , ideally this should be the point of invocation
+func TestMain(m *testing.M) {
+	t := m.Run()
+	os.Exit(t)
+}
+
+// testCert is a generated certificate along with its key
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newCA generates a self-signed CA
+func newCA(t *testing.T, name string) testCert {
+	return newCert(t, name, nil, true)
+}
+
+// newCert generates a certificate signed by the parent, self-signed if there is none.
+// The leaf certificates are valid for localhost and usable by both the servers and the clients
+func newCert(t *testing.T, name string, parent *testCert, isCA bool) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"go-boilerplate"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		template.DNSNames = []string{"localhost"}
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCert{cert: cert, key: key}
+}
+
+// writeFiles writes the certificate and the key in PEM files of the directory and returns their paths
+func (c testCert) writeFiles(t *testing.T, dir string, name string) (string, string) {
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	writeFile(t, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}))
+	writeFile(t, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certFile, keyFile
+}
+
+// writes counts the files written, the modification time of every write is a minute later than the previous one
+var writes int
+
+// writeFile writes the file and moves its modification time forward so that every write is seen as a change
+func writeFile(t *testing.T, file string, content []byte) {
+	err := ioutil.WriteFile(file, content, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writes++
+	modTime := time.Now().Add(time.Duration(writes) * time.Minute)
+	err = os.Chtimes(file, modTime, modTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tempDir creates a temporary directory removed at the end of the test
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// newTestReloader creates a reloader that is closed at the end of the test
+func newTestReloader(t *testing.T, opts Options) *Reloader {
+	r, err := NewReloader(opts, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+// handshake does a TLS handshake between the configs and returns the state of the server side of the connection
+func handshake(t *testing.T, serverConf *tls.Config, clientConf *tls.Config) (tls.ConnectionState, error) {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	type result struct {
+		state tls.ConnectionState
+		err   error
+	}
+	results := make(chan result, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		tlsConn := conn.(*tls.Conn)
+		err = tlsConn.Handshake()
+		results <- result{state: tlsConn.ConnectionState(), err: err}
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), clientConf)
+	if err == nil {
+		// The server verifies the client certificate after the client completes its side of the handshake
+		conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+
+	res := <-results
+	if err != nil {
+		return res.state, err
+	}
+	return res.state, res.err
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := tempDir(t)
+	ca := newCA(t, "ca")
+	caFile, _ := ca.writeFiles(t, dir, "ca")
+	serverCertFile, serverKeyFile := newCert(t, "server", &ca, false).writeFiles(t, dir, "server")
+	clientCertFile, clientKeyFile := newCert(t, "client", &ca, false).writeFiles(t, dir, "client")
+
+	server := newTestReloader(t, Options{CertFile: serverCertFile, KeyFile: serverKeyFile, CAFile: caFile, RequireClientCert: true})
+	serverConf, err := server.ServerConfig()
+	assert.Nil(t, err)
+
+	client := newTestReloader(t, Options{CertFile: clientCertFile, KeyFile: clientKeyFile, CAFile: caFile})
+	state, err := handshake(t, serverConf, client.ClientConfig("localhost"))
+	assert.Nil(t, err)
+
+	// The identity of the client is read from its verified certificate
+	id := identity.FromConnectionState(&state)
+	if assert.NotNil(t, id) {
+		assert.Equal(t, "client", id.CommonName)
+		assert.Equal(t, []string{"go-boilerplate"}, id.Organization)
+	}
+
+	// The client without a certificate is rejected
+	anonymous := newTestReloader(t, Options{CAFile: caFile})
+	_, err = handshake(t, serverConf, anonymous.ClientConfig("localhost"))
+	assert.NotNil(t, err)
+}
+
+func TestOptionalClientCertificate(t *testing.T) {
+	dir := tempDir(t)
+	ca := newCA(t, "ca")
+	caFile, _ := ca.writeFiles(t, dir, "ca")
+	serverCertFile, serverKeyFile := newCert(t, "server", &ca, false).writeFiles(t, dir, "server")
+
+	server := newTestReloader(t, Options{CertFile: serverCertFile, KeyFile: serverKeyFile, CAFile: caFile})
+	serverConf, err := server.ServerConfig()
+	assert.Nil(t, err)
+
+	// The client without a certificate is accepted without an identity
+	anonymous := newTestReloader(t, Options{CAFile: caFile})
+	state, err := handshake(t, serverConf, anonymous.ClientConfig("localhost"))
+	assert.Nil(t, err)
+	assert.Nil(t, identity.FromConnectionState(&state))
+
+	// The certificate of an unknown CA is rejected
+	other := newCA(t, "other")
+	otherCertFile, otherKeyFile := newCert(t, "intruder", &other, false).writeFiles(t, dir, "intruder")
+	intruder := newTestReloader(t, Options{CertFile: otherCertFile, KeyFile: otherKeyFile, CAFile: caFile})
+	_, err = handshake(t, serverConf, intruder.ClientConfig("localhost"))
+	assert.NotNil(t, err)
+}
+
+func TestClientVerifiesServer(t *testing.T) {
+	dir := tempDir(t)
+	ca := newCA(t, "ca")
+	caFile, _ := ca.writeFiles(t, dir, "ca")
+	serverCertFile, serverKeyFile := newCert(t, "server", &ca, false).writeFiles(t, dir, "server")
+
+	server := newTestReloader(t, Options{CertFile: serverCertFile, KeyFile: serverKeyFile})
+	serverConf, err := server.ServerConfig()
+	assert.Nil(t, err)
+
+	// The server certificate is not valid for another name
+	client := newTestReloader(t, Options{CAFile: caFile})
+	_, err = handshake(t, serverConf, client.ClientConfig("ratings"))
+	assert.NotNil(t, err)
+
+	// The server name of the options overrides the one of the connection
+	client = newTestReloader(t, Options{CAFile: caFile, ServerName: "localhost"})
+	_, err = handshake(t, serverConf, client.ClientConfig("ratings"))
+	assert.Nil(t, err)
+}
+
+func TestReloadIfChanged(t *testing.T) {
+	dir := tempDir(t)
+	ca := newCA(t, "ca")
+	certFile, keyFile := newCert(t, "v1", &ca, false).writeFiles(t, dir, "server")
+
+	r := newTestReloader(t, Options{CertFile: certFile, KeyFile: keyFile})
+	leaf := func() string {
+		cert, err := x509.ParseCertificate(r.Certificate().Certificate[0])
+		assert.Nil(t, err)
+		return cert.Subject.CommonName
+	}
+	assert.Equal(t, "v1", leaf())
+
+	// A certificate is replaced once it is written along with its key
+	newCert(t, "v2", &ca, false).writeFiles(t, dir, "server")
+	r.reloadIfChanged()
+	assert.Equal(t, "v2", leaf())
+
+	// The current certificate is kept if the files do not load
+	writeFile(t, keyFile, []byte("invalid"))
+	r.reloadIfChanged()
+	assert.Equal(t, "v2", leaf())
+}
+
+func TestNewReloaderInvalidOptions(t *testing.T) {
+	_, err := NewReloader(Options{MinVersion: "1.0"}, time.Hour)
+	assert.NotNil(t, err)
+
+	_, err = NewReloader(Options{CertFile: "server.crt"}, time.Hour)
+	assert.NotNil(t, err)
+
+	_, err = NewReloader(Options{CAFile: filepath.Join(tempDir(t), "missing.crt")}, time.Hour)
+	assert.NotNil(t, err)
+
+	// A server needs a certificate
+	r := newTestReloader(t, Options{})
+	_, err = r.ServerConfig()
+	assert.NotNil(t, err)
+}
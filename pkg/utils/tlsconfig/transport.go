@@ -0,0 +1,43 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Transport returns an http transport that makes the TLS config of every connection from the reloader,
+// so that the reloaded certificates and CAs apply to the new connections
+func (r *Reloader) Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+	transport.DialTLSContext = func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConn := tls.Client(conn, r.ClientConfig(host))
+		deadline := time.Now().Add(transport.TLSHandshakeTimeout)
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+		tlsConn.SetDeadline(deadline)
+		err = tlsConn.Handshake()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		tlsConn.SetDeadline(time.Time{})
+		return tlsConn, nil
+	}
+	return transport
+}
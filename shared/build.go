@@ -0,0 +1,35 @@
+package shared
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build describes the binary that is running
+type Build struct {
+	// Version is the version set through the ldflags, unknown if it is not set
+	Version   string `json:"version"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	// Module and ModuleVersion are read from the build info embedded by the go tool, if any
+	Module        string `json:"module,omitempty"`
+	ModuleVersion string `json:"moduleVersion,omitempty"`
+}
+
+// BuildInfo returns the version of the app along with the build info
+func BuildInfo() Build {
+	build := Build{
+		Version:   VERSION,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if build.Version == "" {
+		build.Version = "unknown"
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		build.Module = info.Main.Path
+		build.ModuleVersion = info.Main.Version
+	}
+	return build
+}
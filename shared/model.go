@@ -1,11 +1,15 @@
 package shared
 
 import (
+	"io"
+
 	"go-boilerplate-api/apm"
 	"go-boilerplate-api/config"
 	"go-boilerplate-api/pkg/clients/db"
 	grpcPkg "go-boilerplate-api/pkg/clients/grpc"
 	httpPkg "go-boilerplate-api/pkg/clients/http"
+	"go-boilerplate-api/pkg/health"
+	"go-boilerplate-api/pkg/metrics"
 )
 
 // VERSION keeps the version no. (commit id) for global use
@@ -18,4 +22,50 @@ type Deps struct {
 	GrpcConn      grpcPkg.IGrpcConnections
 	HTTPRequester httpPkg.IRequest
 	Apm           apm.HandlerInterface
+	Health        *health.Health
+	Metrics       *metrics.Metrics
+}
+
+// Close closes the dependencies in the reverse order of their initialization.
+// It attempts to close every dependency and returns the first error encountered
+func (deps *Deps) Close() error {
+	var firstErr error
+
+	if deps.HTTPRequester != nil {
+		err := deps.HTTPRequester.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if deps.GrpcConn != nil {
+		err := deps.GrpcConn.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if deps.Database != nil {
+		err := deps.Database.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// Closes the apm handler last so that the spans of the closing connections are exported
+	if closer, ok := deps.Apm.(io.Closer); ok {
+		err := closer.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if deps.Config != nil {
+		err := deps.Config.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
 }